@@ -5,6 +5,7 @@
 package mux
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -79,3 +80,31 @@ func BenchmarkManyPathVariables(b *testing.B) {
 		router.ServeHTTP(recorder, notMatchingRequest)
 	}
 }
+
+func benchmarkManyRoutes(b *testing.B, radix bool) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			router := NewRouter().RadixMatching(radix)
+			handler := func(w http.ResponseWriter, r *http.Request) {}
+			for i := 0; i < n; i++ {
+				router.HandleFunc(fmt.Sprintf("/resource%d/{id:[0-9]+}", i), handler).Methods(http.MethodGet)
+			}
+
+			// The last route registered is the worst case for a linear scan.
+			request, _ := http.NewRequest("GET", fmt.Sprintf("/resource%d/42", n-1), nil)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				router.ServeHTTP(nil, request)
+			}
+		})
+	}
+}
+
+func BenchmarkManyRoutesLinear(b *testing.B) {
+	benchmarkManyRoutes(b, false)
+}
+
+func BenchmarkManyRoutesRadix(b *testing.B) {
+	benchmarkManyRoutes(b, true)
+}
@@ -0,0 +1,227 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Sources of the values bound to struct fields, named after the struct tags
+// selecting them.
+const (
+	bindVar    = "mux"
+	bindQuery  = "query"
+	bindHeader = "header"
+)
+
+// bindField is a struct field tagged for binding.
+type bindField struct {
+	// The name of the field, dotted for fields of embedded structs.
+	Field string
+	Index []int
+	// The tag key and value.
+	Source string
+	Name   string
+}
+
+// bindFields returns the fields of a struct type tagged with mux, query or
+// header, including those of embedded structs.
+func bindFields(t reflect.Type) []bindField {
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag == "" {
+			for _, ef := range bindFields(f.Type) {
+				ef.Field = f.Name + "." + ef.Field
+				ef.Index = append([]int{i}, ef.Index...)
+				fields = append(fields, ef)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		for _, source := range []string{bindVar, bindQuery, bindHeader} {
+			if name, ok := f.Tag.Lookup(source); ok && name != "" && name != "-" {
+				fields = append(fields, bindField{Field: f.Name, Index: []int{i}, Source: source, Name: name})
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// FieldError describes a struct field that Bind failed to fill.
+type FieldError struct {
+	// The name of the field, dotted for fields of embedded structs.
+	Field string
+	// The struct tag selecting the value: "mux", "query" or "header".
+	Source string
+	// The name of the route variable, query parameter or header.
+	Name string
+	// The value that failed to convert.
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s (%s %q): invalid value %q: %v", e.Field, e.Source, e.Name, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindError is returned by Bind when some fields can't be filled.
+type BindError struct {
+	// The fields that failed, in the order of the struct.
+	Fields []*FieldError
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return "mux: cannot bind request: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the fields, for errors.Is and errors.As.
+func (e *BindError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Bind fills the struct pointed to by dst with the route variables, query
+// parameters and headers of a request, selected by struct tags:
+//
+//	type ListParams struct {
+//		Category string    `mux:"category"`
+//		Page     int       `query:"page"`
+//		Tags     []string  `query:"tag"`
+//		Since    time.Time `query:"since"`
+//		Tenant   string    `header:"X-Tenant"`
+//	}
+//
+//	var params ListParams
+//	if err := mux.Bind(req, &params); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+//
+// Values are converted like Var does. Slice fields get every value of a
+// query parameter or header. Pointer fields are allocated when there is a
+// value. Fields whose value is missing are left unchanged, as are untagged
+// fields; the fields of embedded structs without tags are filled too.
+//
+// Bind returns a *BindError listing every field whose value can't be
+// converted, after filling the others.
+func Bind(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("mux: Bind needs a non-nil pointer to a struct, got %T", dst)
+	}
+	v = v.Elem()
+	vars := Vars(r)
+	query := r.URL.Query()
+	var bindErr BindError
+	for _, f := range bindFields(v.Type()) {
+		var values []string
+		switch f.Source {
+		case bindVar:
+			if value, ok := vars[f.Name]; ok {
+				values = []string{value}
+			}
+		case bindQuery:
+			values = query[f.Name]
+		case bindHeader:
+			values = r.Header.Values(f.Name)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if value, err := setField(v.FieldByIndex(f.Index), values); err != nil {
+			bindErr.Fields = append(bindErr.Fields, &FieldError{
+				Field:  f.Field,
+				Source: f.Source,
+				Name:   f.Name,
+				Value:  value,
+				Err:    err,
+			})
+		}
+	}
+	if len(bindErr.Fields) > 0 {
+		return &bindErr
+	}
+	return nil
+}
+
+// setField converts values to the type of a field and stores them in the
+// field. It returns the value that failed to convert with the error.
+func setField(v reflect.Value, values []string) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if value, err := setField(elem.Elem(), values); err != nil {
+			return value, err
+		}
+		v.Set(elem)
+		return "", nil
+	}
+	if v.Kind() == reflect.Slice && v.Type() != timeType && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(s.Index(i), value); err != nil {
+				return value, err
+			}
+		}
+		v.Set(s)
+		return "", nil
+	}
+	// Converted in a copy, so that the field is left unchanged on error.
+	elem := reflect.New(v.Type()).Elem()
+	if err := setValue(elem, values[0]); err != nil {
+		return values[0], err
+	}
+	v.Set(elem)
+	return "", nil
+}
+
+// CheckBind verifies that the fields of v, a struct or a pointer to a
+// struct, tagged with mux name variables of the route. It is meant to be
+// called when registering a route, to catch typos in the tags of the struct
+// its handler passes to Bind:
+//
+//	r.HandleFunc("/articles/{category}/{id:int}", ArticleHandler).CheckBind(ArticleParams{})
+//
+// Otherwise, the route gets an error, see GetError.
+func (r *Route) CheckBind(v any) *Route {
+	if r.err != nil {
+		return r
+	}
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		r.err = fmt.Errorf("mux: CheckBind needs a struct or a pointer to a struct, got %T", v)
+		return r
+	}
+	names, _ := r.GetVarNames()
+	var errs []error
+	for _, f := range bindFields(t) {
+		if f.Source == bindVar && !matchInArray(names, f.Name) {
+			errs = append(errs, fmt.Errorf("mux: field %s of %s binds route variable %q, not in route %s", f.Field, t, f.Name, r.describe()))
+		}
+	}
+	r.err = errors.Join(errs...)
+	return r
+}
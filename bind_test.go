@@ -0,0 +1,126 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindPage struct {
+	Page  int  `query:"page"`
+	Limit *int `query:"limit"`
+}
+
+type bindParams struct {
+	bindPage
+	Category string     `mux:"category"`
+	ID       uint64     `mux:"id"`
+	Tags     []string   `query:"tag"`
+	IDs      []int      `query:"ids"`
+	Since    time.Time  `query:"since"`
+	Draft    bool       `query:"draft"`
+	Addr     netip.Addr `query:"addr"`
+	Tenant   string     `header:"X-Tenant"`
+	Accept   []string   `header:"Accept"`
+	Ignored  string     `query:"-"`
+	Untagged string
+	hidden   string `query:"hidden"`
+}
+
+func TestBind(t *testing.T) {
+	req := newRequest(http.MethodGet, "http://localhost/articles/news/42?page=2&limit=10&tag=a&tag=b&ids=1&ids=2&since=2024-01-02&draft=true&addr=127.0.0.1&Ignored=x&hidden=x")
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Add("Accept", "text/html")
+	req.Header.Add("Accept", "application/json")
+	req = SetURLVars(req, map[string]string{"category": "news", "id": "42"})
+
+	params := bindParams{Untagged: "kept"}
+	if err := Bind(req, &params); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	limit := 10
+	want := bindParams{
+		bindPage: bindPage{Page: 2, Limit: &limit},
+		Category: "news",
+		ID:       42,
+		Tags:     []string{"a", "b"},
+		IDs:      []int{1, 2},
+		Since:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Draft:    true,
+		Addr:     netip.MustParseAddr("127.0.0.1"),
+		Tenant:   "acme",
+		Accept:   []string{"text/html", "application/json"},
+		Untagged: "kept",
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Expected %+v, got %+v", want, params)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	req := newRequest(http.MethodGet, "http://localhost/articles/news/x?page=two&ids=1&ids=b&draft=true")
+	req = SetURLVars(req, map[string]string{"category": "news", "id": "x"})
+
+	params := bindParams{ID: 7}
+	err := Bind(req, &params)
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("Expected a *BindError, got %v", err)
+	}
+	var fields []string
+	for _, f := range bindErr.Fields {
+		fields = append(fields, f.Field+"="+f.Value)
+	}
+	if want := []string{"bindPage.Page=two", "ID=x", "IDs=b"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected failed fields %v, got %v", want, fields)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected the error to wrap the conversion errors, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `field ID (mux "id"): invalid value "x"`) {
+		t.Errorf("Unexpected error message %q", err)
+	}
+	// The other fields are filled, the failed ones left unchanged.
+	if params.Category != "news" || !params.Draft || params.ID != 7 || params.IDs != nil {
+		t.Errorf("Unexpected fields %+v", params)
+	}
+
+	for _, dst := range []any{nil, params, new(int), (*bindParams)(nil)} {
+		if err := Bind(req, dst); err == nil || errors.As(err, &bindErr) {
+			t.Errorf("%T: expected an invalid argument error, got %v", dst, err)
+		}
+	}
+}
+
+func TestRouteCheckBind(t *testing.T) {
+	r := NewRouter()
+	tests := []struct {
+		route *Route
+		err   string
+	}{
+		{r.Path("/articles/{category}/{id:int}").CheckBind(bindParams{}), ""},
+		{r.Host("{category}.example.com").Path("/{id}").CheckBind(&bindParams{}), ""},
+		{r.Path("/articles/{category}").CheckBind(bindParams{}), `field ID of mux.bindParams binds route variable "id", not in route "/articles/{category}"`},
+		{r.Path("/articles").CheckBind(bindParams{}), `route variable "category"`},
+		{r.Path("/articles").CheckBind("x"), "needs a struct"},
+	}
+	for i, tt := range tests {
+		err := tt.route.GetError()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("Route %d: unexpected error %v", i, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Route %d: expected an error containing %q, got %v", i, tt.err, err)
+		}
+	}
+}
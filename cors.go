@@ -0,0 +1,237 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the responses of CORSMiddleware to cross-origin
+// requests.
+type CORSOptions struct {
+	// The origins allowed, such as "https://example.com", compared without
+	// regard to case. "*" allows any origin, but can't be combined with
+	// AllowCredentials: credentialed requests need the origins listed or
+	// AllowOriginFunc.
+	AllowedOrigins []string
+	// If set, also allows the origins for which it returns true.
+	AllowOriginFunc func(origin string) bool
+	// The request headers allowed in preflight requests, compared without
+	// regard to case. "*" allows any header.
+	AllowedHeaders []string
+	// The response headers exposed to the client.
+	ExposedHeaders []string
+	// Whether the client may send credentials such as cookies.
+	AllowCredentials bool
+	// How long the client may cache preflight responses, rounded down to
+	// seconds. Zero omits the Access-Control-Max-Age header.
+	MaxAge time.Duration
+}
+
+// corsMetadataKey is the metadata key of the CORSOptions of a route.
+const corsMetadataKey metadataKey = "mux.cors"
+
+// metadataKey is the type of the route metadata keys set by this package.
+type metadataKey string
+
+// ErrCORSWildcardCredentials is returned by Route.CORS, and CORSMiddleware
+// panics with it, for options allowing any origin with credentials.
+var ErrCORSWildcardCredentials = errors.New(`mux: CORS origin "*" can't be used with credentials, list the origins or set AllowOriginFunc`)
+
+// CORS sets the CORSOptions used by CORSMiddleware for requests matching the
+// route, replacing the defaults of the middleware. The options are stored in
+// the route metadata. Options allowing any origin with credentials set the
+// route error to ErrCORSWildcardCredentials.
+func (r *Route) CORS(opts CORSOptions) *Route {
+	if err := opts.validate(); err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return r
+	}
+	return r.Metadata(corsMetadataKey, opts)
+}
+
+// CORSMiddleware returns a middleware handling cross-origin requests to the
+// routes of r, using the options set with Route.CORS on the matching route, or
+// defaults if there are none.
+//
+// Preflight requests for an allowed origin, method and headers are answered
+// without calling the next handler, with Access-Control-Allow-Methods listing
+// the methods of the routes matching the request path, like
+// CORSMethodMiddleware does. Other preflight requests are passed to the next
+// handler without CORS headers. Other requests get the
+// Access-Control-Allow-Origin, Access-Control-Allow-Credentials and
+// Access-Control-Expose-Headers headers before calling the next handler.
+// Responses get a Vary header listing the request headers they depend on.
+//
+// Router middlewares only run for matching requests, so preflight requests
+// need an OPTIONS route or Router.AutoOptions to reach a middleware added
+// with Router.Use. Alternatively, the middleware can wrap the router:
+//
+//	http.ListenAndServe(":8080", mux.CORSMiddleware(r, opts)(r))
+//
+// CORSMiddleware panics with ErrCORSWildcardCredentials if defaults allow any
+// origin with credentials.
+func CORSMiddleware(r *Router, defaults CORSOptions) MiddlewareFunc {
+	if err := defaults.validate(); err != nil {
+		panic(err)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			reqMethod := req.Header.Get("Access-Control-Request-Method")
+			if req.Method == http.MethodOptions && origin != "" && reqMethod != "" {
+				if !serveCORSPreflight(r, defaults, w, req) {
+					next.ServeHTTP(w, req)
+				}
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+			if origin != "" {
+				route := CurrentRoute(req)
+				if route == nil {
+					var match RouteMatch
+					if r.Match(req, &match) {
+						route = match.Route
+					}
+				}
+				opts := routeCORSOptions(route, defaults)
+				if allowOrigin, ok := opts.allowOrigin(origin); ok {
+					h := w.Header()
+					h.Set("Access-Control-Allow-Origin", allowOrigin)
+					if opts.AllowCredentials {
+						h.Set("Access-Control-Allow-Credentials", "true")
+					}
+					if len(opts.ExposedHeaders) > 0 {
+						h.Set("Access-Control-Expose-Headers", strings.Join(opts.ExposedHeaders, ", "))
+					}
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// serveCORSPreflight answers a preflight request, and reports whether it did.
+// It doesn't if the origin, method or headers are not allowed.
+func serveCORSPreflight(r *Router, defaults CORSOptions, w http.ResponseWriter, req *http.Request) bool {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	// The options are those of the route serving the actual request.
+	reqMethod := req.Header.Get("Access-Control-Request-Method")
+	actual := req.WithContext(req.Context())
+	actual.Method = reqMethod
+	var match RouteMatch
+	if !r.Match(actual, &match) || match.MatchErr != nil {
+		return false
+	}
+	opts := routeCORSOptions(match.Route, defaults)
+	allowOrigin, ok := opts.allowOrigin(req.Header.Get("Origin"))
+	if !ok {
+		return false
+	}
+	headers, ok := opts.allowHeaders(req.Header.Get("Access-Control-Request-Headers"))
+	if !ok {
+		return false
+	}
+
+	methods, err := getAllMethodsForRoute(r, req)
+	if err != nil {
+		// A matching route has no methods matcher and accepts any method.
+		methods = []string{reqMethod}
+	}
+	var allowed []string
+	for _, m := range methods {
+		if !matchInArray(allowed, m) {
+			allowed = append(allowed, m)
+		}
+	}
+
+	h.Set("Access-Control-Allow-Origin", allowOrigin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+	if headers != "" {
+		h.Set("Access-Control-Allow-Headers", headers)
+	}
+	if opts.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if seconds := int64(opts.MaxAge / time.Second); seconds > 0 {
+		h.Set("Access-Control-Max-Age", strconv.FormatInt(seconds, 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// routeCORSOptions returns the CORSOptions of a route, or defaults.
+func routeCORSOptions(route *Route, defaults CORSOptions) *CORSOptions {
+	if route != nil {
+		if opts, ok := route.GetMetadataValueOr(corsMetadataKey, nil).(CORSOptions); ok {
+			return &opts
+		}
+	}
+	return &defaults
+}
+
+// validate reports options allowing any origin with credentials.
+func (o *CORSOptions) validate() error {
+	if o.AllowCredentials && matchInArray(o.AllowedOrigins, "*") {
+		return ErrCORSWildcardCredentials
+	}
+	return nil
+}
+
+// allowOrigin returns the value of the Access-Control-Allow-Origin header
+// for an origin, and whether the origin is allowed. The wildcard origin is
+// ignored with credentials, see CORSOptions.AllowedOrigins.
+func (o *CORSOptions) allowOrigin(origin string) (string, bool) {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" {
+			if o.AllowCredentials {
+				continue
+			}
+			return "*", true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+	}
+	if o.AllowOriginFunc != nil && o.AllowOriginFunc(origin) {
+		return origin, true
+	}
+	return "", false
+}
+
+// allowHeaders returns the value of the Access-Control-Allow-Headers header
+// for the comma-separated headers of a preflight request, and whether they
+// are all allowed.
+func (o *CORSOptions) allowHeaders(requested string) (string, bool) {
+	var headers []string
+	for _, header := range strings.Split(requested, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, http.CanonicalHeaderKey(header))
+		}
+	}
+	for _, header := range headers {
+		allowed := false
+		for _, a := range o.AllowedHeaders {
+			if a == "*" || strings.EqualFold(a, header) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", false
+		}
+	}
+	return strings.Join(headers, ", "), true
+}
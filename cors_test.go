@@ -0,0 +1,246 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	called := false
+	router := NewRouter()
+	router.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/items", dummyHandler).Methods(http.MethodDelete).CORS(CORSOptions{
+		AllowedOrigins: []string{"https://admin.example.com"},
+	})
+	handler := CORSMiddleware(router, CORSOptions{
+		AllowedOrigins:   []string{"https://example.com"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})(router)
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		code    int
+		want    map[string]string
+	}{
+		{
+			name:    "allowed",
+			origin:  "https://example.com",
+			method:  http.MethodPost,
+			headers: "content-type, authorization",
+			code:    http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "https://example.com",
+				"Access-Control-Allow-Methods":     "GET, POST, DELETE",
+				"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:   "origin not allowed",
+			origin: "https://evil.example.com",
+			method: http.MethodGet,
+			code:   http.StatusMethodNotAllowed,
+			want:   map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:    "header not allowed",
+			origin:  "https://example.com",
+			method:  http.MethodGet,
+			headers: "X-Custom",
+			code:    http.StatusMethodNotAllowed,
+			want:    map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:   "method not allowed",
+			origin: "https://example.com",
+			method: http.MethodPut,
+			code:   http.StatusMethodNotAllowed,
+			want:   map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:   "route options",
+			origin: "https://admin.example.com",
+			method: http.MethodDelete,
+			code:   http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "https://admin.example.com",
+				"Access-Control-Allow-Credentials": "",
+				"Access-Control-Max-Age":           "",
+			},
+		},
+		{
+			name:   "route options replace defaults",
+			origin: "https://example.com",
+			method: http.MethodDelete,
+			code:   http.StatusMethodNotAllowed,
+			want:   map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			req := newRequest(http.MethodOptions, "/items")
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.code {
+				t.Errorf("Expected status code %d, got %d", tt.code, w.Code)
+			}
+			if called {
+				t.Error("Expected the handler not to be called")
+			}
+			for k, v := range tt.want {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("Expected %s %q, got %q", k, v, got)
+				}
+			}
+			vary := strings.Join(w.Header().Values("Vary"), ", ")
+			if vary != "Origin, Access-Control-Request-Method, Access-Control-Request-Headers" {
+				t.Errorf("Unexpected Vary header %q", vary)
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareActualRequest(t *testing.T) {
+	router := NewRouter()
+	router.HandleFunc("/items", stringHandler("items")).Methods(http.MethodGet)
+	router.HandleFunc("/public", stringHandler("public")).Methods(http.MethodGet).CORS(CORSOptions{
+		AllowedOrigins: []string{"*"},
+	})
+	router.Use(CORSMiddleware(router, CORSOptions{
+		AllowedOrigins:   []string{"https://example.com"},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+	}))
+
+	tests := []struct {
+		path   string
+		origin string
+		want   map[string]string
+	}{
+		{"/items", "https://EXAMPLE.com", map[string]string{
+			"Access-Control-Allow-Origin":      "https://EXAMPLE.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Expose-Headers":    "X-Total-Count",
+		}},
+		{"/items", "https://evil.example.com", map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"/public", "https://evil.example.com", map[string]string{
+			"Access-Control-Allow-Origin":      "*",
+			"Access-Control-Allow-Credentials": "",
+		}},
+		{"/items", "", map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+	}
+	for _, tt := range tests {
+		req := newRequest(http.MethodGet, tt.path)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Body.String() != tt.path[1:] {
+			t.Errorf("%s %s: expected the handler to be called, got %q", tt.path, tt.origin, w.Body.String())
+		}
+		for k, v := range tt.want {
+			if got := w.Header().Get(k); got != v {
+				t.Errorf("%s %s: expected %s %q, got %q", tt.path, tt.origin, k, v, got)
+			}
+		}
+		if vary := w.Header().Get("Vary"); vary != "Origin" {
+			t.Errorf("%s %s: expected Vary header %q, got %q", tt.path, tt.origin, "Origin", vary)
+		}
+	}
+}
+
+func TestCORSMiddlewareAutoOptions(t *testing.T) {
+	router := NewRouter().AutoOptions(true)
+	router.HandleFunc("/items", dummyHandler).Methods(http.MethodGet)
+	router.Use(CORSMiddleware(router, CORSOptions{AllowedOrigins: []string{"*"}}))
+
+	req := newRequest(http.MethodOptions, "/items")
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status code 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", "*", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != http.MethodGet {
+		t.Errorf("Expected Access-Control-Allow-Methods %q, got %q", http.MethodGet, got)
+	}
+}
+
+func TestCORSWildcardCredentials(t *testing.T) {
+	opts := CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+
+	func() {
+		defer func() {
+			if r := recover(); r != ErrCORSWildcardCredentials {
+				t.Errorf("Expected CORSMiddleware to panic with %v, got %v", ErrCORSWildcardCredentials, r)
+			}
+		}()
+		CORSMiddleware(NewRouter(), opts)
+	}()
+
+	router := NewRouter()
+	route := router.HandleFunc("/items", dummyHandler).CORS(opts)
+	if err := route.GetError(); err != ErrCORSWildcardCredentials {
+		t.Errorf("Expected the route error %v, got %v", ErrCORSWildcardCredentials, err)
+	}
+
+	// The wildcard is ignored if the options are set as metadata.
+	router.HandleFunc("/meta", dummyHandler).Metadata(corsMetadataKey, opts)
+	router.Use(CORSMiddleware(router, CORSOptions{}))
+	req := newRequest(http.MethodGet, "/meta")
+	req.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no Access-Control-Allow-Origin, got %q", got)
+	}
+
+	// Credentials can be allowed for any origin accepted by AllowOriginFunc.
+	opts = CORSOptions{
+		AllowOriginFunc:  func(origin string) bool { return strings.HasSuffix(origin, ".example.com") },
+		AllowCredentials: true,
+	}
+	router = NewRouter()
+	router.HandleFunc("/items", dummyHandler)
+	router.Use(CORSMiddleware(router, opts))
+	req = newRequest(http.MethodGet, "/items")
+	req.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", "https://app.example.com", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Expected Access-Control-Allow-Credentials %q, got %q", "true", got)
+	}
+}
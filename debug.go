@@ -0,0 +1,185 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// RouteInfo is a snapshot of the configuration of a route. See Router.Routes.
+type RouteInfo struct {
+	// The route described.
+	Route *Route `json:"-"`
+	Name  string `json:"name,omitempty"`
+	Host  string `json:"host,omitempty"`
+	// The full path template, including the path prefixes of the parent
+	// routes.
+	Path       string   `json:"path,omitempty"`
+	PathPrefix bool     `json:"pathPrefix,omitempty"`
+	Methods    []string `json:"methods,omitempty"`
+	Queries    []string `json:"queries,omitempty"`
+	Schemes    []string `json:"schemes,omitempty"`
+	// The metadata keys, formatted with fmt.Sprint and sorted.
+	MetadataKeys []string `json:"metadataKeys,omitempty"`
+	// The number of middlewares wrapping the route handler, including those
+	// of the route, of its router and of the routers above it.
+	Middlewares int `json:"middlewares"`
+	// The indexes of the ancestor routes in the snapshot, outermost first.
+	Ancestors []int `json:"ancestors,omitempty"`
+	// The limits of the route, see Route.Timeout and Route.MaxBodyBytes.
+	Timeout      time.Duration `json:"timeout,omitempty"`
+	MaxBodyBytes int64         `json:"maxBodyBytes,omitempty"`
+	// Whether the route has a subrouter or a Router as handler.
+	Subrouter bool `json:"subrouter,omitempty"`
+	// Whether the route was registered with Router.Mount.
+	Mount     bool   `json:"mount,omitempty"`
+	BuildOnly bool   `json:"buildOnly,omitempty"`
+	Disabled  bool   `json:"disabled,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Routes returns a snapshot of the routes of the router and its subrouters,
+// including routers used as route handlers, in the order Walk visits them.
+func (r *Router) Routes() []RouteInfo {
+	if r.conflicts != nil {
+		r.conflicts.check()
+	}
+	var infos []RouteInfo
+	// The index in infos and the router of the routes visited.
+	index := make(map[*Route]int)
+	owner := make(map[*Route]*Router)
+	_ = r.Walk(func(route *Route, router *Router, ancestors []*Route) error {
+		index[route] = len(infos)
+		owner[route] = router
+
+		info := RouteInfo{
+			Route:        route,
+			Name:         route.name,
+			Middlewares:  len(middlewareChain(route, router, ancestors, owner)),
+			Subrouter:    len(route.subrouters()) > 0,
+			Mount:        route.mount,
+			Timeout:      route.timeout,
+			MaxBodyBytes: route.maxBodyBytes,
+			BuildOnly:    route.buildOnly,
+			Disabled:     route.IsDisabled(),
+		}
+		if err := route.GetError(); err != nil {
+			info.Error = err.Error()
+		}
+		if route.regexp.host != nil {
+			info.Host = route.regexp.host.template
+		}
+		if route.regexp.path != nil {
+			info.Path = route.regexp.path.template
+			info.PathPrefix = route.regexp.path.regexpType == regexpTypePrefix
+		}
+		for _, q := range route.regexp.queries {
+			info.Queries = append(info.Queries, q.template)
+		}
+		for _, m := range route.matchers {
+			switch m := m.(type) {
+			// The route only matches the methods and schemes of all its
+			// matchers.
+			case methodMatcher:
+				info.Methods = intersectStrings(info.Methods, m)
+			case schemeMatcher:
+				info.Schemes = intersectStrings(info.Schemes, m)
+			}
+		}
+		for k := range route.metadata {
+			info.MetadataKeys = append(info.MetadataKeys, fmt.Sprint(k))
+		}
+		sort.Strings(info.MetadataKeys)
+		for _, a := range ancestors {
+			info.Ancestors = append(info.Ancestors, index[a])
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	return infos
+}
+
+// DebugHandler returns a handler serving the routes of a router, see
+// Router.Routes, as a plain-text table, or as JSON if the request has a
+// format=json query parameter or accepts application/json. For example:
+//
+//	r.Handle("/debug/routes", mux.DebugHandler(r))
+//
+// The handler exposes the whole routing configuration and should not be
+// reachable by untrusted clients.
+func DebugHandler(router *Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		routes := router.Routes()
+		if req.URL.Query().Get("format") == "json" || strings.Contains(req.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(routes)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeRouteTable(w, routes)
+	})
+}
+
+// writeRouteTable writes routes as a table, indenting subrouter routes.
+func writeRouteTable(w io.Writer, routes []RouteInfo) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMETHODS\tHOST\tPATH\tQUERIES\tSCHEMES\tMIDDLEWARES\tFLAGS")
+	for _, info := range routes {
+		path := strings.Repeat("  ", len(info.Ancestors)) + orDash(info.Path)
+		if info.PathPrefix {
+			path += "*"
+		}
+		var flags []string
+		if info.Subrouter {
+			flags = append(flags, "subrouter")
+		}
+		if info.Mount {
+			flags = append(flags, "mount")
+		}
+		if info.Timeout > 0 {
+			flags = append(flags, "timeout="+info.Timeout.String())
+		}
+		if info.MaxBodyBytes > 0 {
+			flags = append(flags, "max-body="+strconv.FormatInt(info.MaxBodyBytes, 10))
+		}
+		if info.BuildOnly {
+			flags = append(flags, "build-only")
+		}
+		if info.Disabled {
+			flags = append(flags, "disabled")
+		}
+		if info.Error != "" {
+			flags = append(flags, "error: "+info.Error)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			orDash(info.Name),
+			orDash(strings.Join(info.Methods, ",")),
+			orDash(info.Host),
+			path,
+			orDash(strings.Join(info.Queries, "&")),
+			orDash(strings.Join(info.Schemes, ",")),
+			info.Middlewares,
+			orDash(strings.Join(flags, ",")))
+	}
+	_ = tw.Flush()
+}
+
+// orDash returns s, or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
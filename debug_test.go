@@ -0,0 +1,112 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func debugTestRouter() *Router {
+	mw := func(h http.Handler) http.Handler { return h }
+	r := NewRouter()
+	r.Use(mw)
+	r.HandleFunc("/users/{id}", dummyHandler).Methods(http.MethodGet).Name("user").Metadata("owner", "team-a")
+	api := r.Host("api.example.com").PathPrefix("/api").Subrouter()
+	api.Use(mw, mw)
+	api.HandleFunc("/items", dummyHandler).Queries("page", "{page}").Schemes("https")
+	mounted := NewRouter()
+	mounted.HandleFunc("/status", dummyHandler)
+	r.PathPrefix("/admin").Handler(mounted).Use(mw)
+	r.HandleFunc("/built", dummyHandler).BuildOnly()
+	r.HandleFunc("/off", dummyHandler).Disable()
+	r.Path("/{a}").Host("{a}")
+	return r
+}
+
+func TestRoutes(t *testing.T) {
+	routes := debugTestRouter().Routes()
+	if len(routes) != 8 {
+		t.Fatalf("Expected 8 routes, got %d", len(routes))
+	}
+	for i := range routes {
+		if routes[i].Route == nil {
+			t.Errorf("Route %d: expected the route to be set", i)
+		}
+		routes[i].Route = nil
+	}
+	want := []RouteInfo{
+		{Name: "user", Path: "/users/{id}", Methods: []string{"GET"}, MetadataKeys: []string{"owner"}, Middlewares: 1},
+		{Host: "api.example.com", Path: "/api", PathPrefix: true, Middlewares: 1, Subrouter: true},
+		{Host: "api.example.com", Path: "/api/items", Queries: []string{"page={page}"}, Schemes: []string{"https"}, Middlewares: 3, Ancestors: []int{1}},
+		{Path: "/admin", PathPrefix: true, Middlewares: 2, Subrouter: true},
+		{Path: "/status", Middlewares: 2, Ancestors: []int{3}},
+		{Path: "/built", Middlewares: 1, BuildOnly: true},
+		{Path: "/off", Middlewares: 1, Disabled: true},
+		{Error: `mux: duplicated route variable "a"`, Path: "/{a}", Middlewares: 1},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(routes[i], want[i]) {
+			t.Errorf("Route %d: expected %+v, got %+v", i, want[i], routes[i])
+		}
+	}
+}
+
+func TestRoutesChainedMatchers(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/posts", dummyHandler).
+		Methods(http.MethodGet, http.MethodPost).Methods(http.MethodPost, http.MethodPut).
+		Schemes("http", "https").Schemes("https")
+	routes := r.Routes()
+	if len(routes) != 1 {
+		t.Fatalf("Expected 1 route, got %d", len(routes))
+	}
+	if want := []string{http.MethodPost}; !reflect.DeepEqual(routes[0].Methods, want) {
+		t.Errorf("Expected methods %v, got %v", want, routes[0].Methods)
+	}
+	if want := []string{"https"}; !reflect.DeepEqual(routes[0].Schemes, want) {
+		t.Errorf("Expected schemes %v, got %v", want, routes[0].Schemes)
+	}
+}
+
+func TestDebugHandler(t *testing.T) {
+	h := DebugHandler(debugTestRouter())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost/debug/routes?format=json"))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON response, got %q", ct)
+	}
+	var routes []RouteInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil || len(routes) != 8 || routes[0].Name != "user" {
+		t.Errorf("Unexpected JSON response %s: %v", rec.Body, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost/debug/routes"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("Expected a header and 8 rows, got:\n%s", rec.Body)
+	}
+	for i, want := range []string{
+		"NAME",
+		"user",
+		"/api*",
+		"    /api/items",
+		"/admin*",
+		"    /status",
+		"build-only",
+		"disabled",
+		"error: mux: duplicated route variable",
+	} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("Expected line %d to contain %q, got %q", i, want, lines[i])
+		}
+	}
+}
@@ -47,16 +47,55 @@ variable will be anything until the next slash. For example:
 	r.HandleFunc("/articles/{category}/", ArticlesCategoryHandler)
 	r.HandleFunc("/articles/{category}/{id:[0-9]+}", ArticleHandler)
 
+A path can end with a wildcard variable, {name...}, which matches the rest of
+the path including slashes:
+
+	r.HandleFunc("/files/{path...}", FileHandler)
+
+With the OptionalParts option, parts of a path can be made optional by
+enclosing them in square brackets, and variables can have a default value,
+used when they are not matched:
+
+	r := mux.NewRouter().OptionalParts(true)
+	r.HandleFunc("/reports[/{year:[0-9]{4}}][.{format=json}]", ReportsHandler)
+
+Here "/reports" and "/reports/2024.csv" both match, and mux.Vars contains
+"format": "json" for the former. When building URLs, optional parts are left
+out if their variables are not given.
+
 Groups can be used inside patterns, as long as they are non-capturing (?:re). For example:
 
 	r.HandleFunc("/articles/{category}/{sort:(?:asc|desc|new)}", ArticlesCategoryHandler)
 
+A few type shortcuts can be used instead of a pattern: int, uint, uuid, slug,
+date (as in 2006-01-02), hex and alpha. They expand to suitable regular
+expressions, and the value can be read converted with mux.Var or mux.VarInt:
+
+	r.HandleFunc("/articles/{id:int}", ArticleHandler)
+
+	id, err := mux.VarInt(request, "id")
+
+Patterns shared by many routes can be registered once on the router and
+referenced by name with an @ prefix:
+
+	r.RegisterPattern("sha", "[0-9a-f]{40}")
+	r.HandleFunc("/commits/{commit:@sha}", CommitHandler)
+
 The names are used to create a map of route variables which can be retrieved
 calling mux.Vars():
 
 	vars := mux.Vars(request)
 	category := vars["category"]
 
+Route variables, query parameters and headers can also be converted into the
+fields of a struct with mux.Bind, using struct tags:
+
+	var params struct {
+		Category string `mux:"category"`
+		Page     int    `query:"page"`
+	}
+	err := mux.Bind(request, &params)
+
 Note that if any capturing groups are present, mux will panic() during parsing. To prevent
 this, convert any capturing groups to non-capturing, e.g. change "/{sort:(asc|desc)}" to
 "/{sort:(?:asc|desc)}". This is a change from prior versions which behaved unpredictably
@@ -215,6 +254,11 @@ Regex support also exists for matching Headers within a route. For example, we c
 ...and the route will match both requests with a Content-Type of `application/json` as well as
 `application/text`
 
+URLs can also be built from a map or from a struct tagged like for mux.Bind,
+with extra query parameters and a fragment:
+
+	url, err := r.Get("article").URLFromStruct(params, mux.URLOptions{Fragment: "top"})
+
 There's also a way to build only the URL host or path for a route:
 use the methods URLHost() or URLPath() instead. For the previous route,
 we would do:
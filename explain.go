@@ -0,0 +1,305 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// MatcherKind identifies the kind of a matcher in a MatcherTrace.
+type MatcherKind int
+
+const (
+	// MatcherPath is a matcher added by Route.Path.
+	MatcherPath MatcherKind = iota
+	// MatcherPathPrefix is a matcher added by Route.PathPrefix.
+	MatcherPathPrefix
+	// MatcherHost is a matcher added by Route.Host.
+	MatcherHost
+	// MatcherQuery is a matcher added by Route.Queries, one per pair.
+	MatcherQuery
+	// MatcherHeaders is a matcher added by Route.Headers or
+	// Route.HeadersRegexp.
+	MatcherHeaders
+	// MatcherSchemes is a matcher added by Route.Schemes.
+	MatcherSchemes
+	// MatcherMethods is a matcher added by Route.Methods.
+	MatcherMethods
+	// MatcherSubrouter is the matcher added by Route.Subrouter.
+	MatcherSubrouter
+	// MatcherCustom is a matcher added by Route.MatcherFunc.
+	MatcherCustom
+)
+
+func (k MatcherKind) String() string {
+	switch k {
+	case MatcherPath:
+		return "path"
+	case MatcherPathPrefix:
+		return "path prefix"
+	case MatcherHost:
+		return "host"
+	case MatcherQuery:
+		return "query"
+	case MatcherHeaders:
+		return "headers"
+	case MatcherSchemes:
+		return "schemes"
+	case MatcherMethods:
+		return "methods"
+	case MatcherSubrouter:
+		return "subrouter"
+	case MatcherCustom:
+		return "custom"
+	}
+	return fmt.Sprintf("MatcherKind(%d)", int(k))
+}
+
+// MatchTrace describes how a router evaluated a request. See Router.Explain.
+type MatchTrace struct {
+	// The traces of the routes of the router, in registration order.
+	Routes []RouteTrace
+	// The result of Router.Match for the request.
+	Matched bool
+	Match   RouteMatch
+}
+
+// RouteTrace describes how a route evaluated a request.
+type RouteTrace struct {
+	Route *Route
+	// Why the route was not evaluated: "build-only", "disabled" or the error
+	// of the route. Empty if the route was evaluated.
+	Skipped string
+	// The matchers evaluated, in order. As in Route.Match, evaluation stops
+	// at the first matcher that fails, unless it is a methods matcher.
+	Matchers []MatcherTrace
+	// Whether all the matchers matched.
+	Matched bool
+	// The variables extracted by the host, path and query matchers that
+	// matched.
+	Vars map[string]string
+}
+
+// Failed returns the first matcher that did not match, or nil.
+func (t *RouteTrace) Failed() *MatcherTrace {
+	for i := range t.Matchers {
+		if !t.Matchers[i].Matched {
+			return &t.Matchers[i]
+		}
+	}
+	return nil
+}
+
+// MatcherTrace describes how a matcher evaluated a request.
+type MatcherTrace struct {
+	Kind MatcherKind
+	// The template, methods, schemes or headers matched, or the name of the
+	// function of a custom matcher.
+	Value   string
+	Matched bool
+	// For a subrouter, the traces of its routes.
+	Routes []RouteTrace
+}
+
+// Explain evaluates a request against every route of the router, descending
+// into subrouters, and reports which matchers each route evaluated, which
+// one failed and the variables extracted so far. Unlike Match, it doesn't
+// stop at the first matching route; the route Match selects is available in
+// the Match field of the trace.
+//
+// Explain doesn't clean the request path as ServeHTTP does. Custom matchers
+// may be called more than once. It is meant for debugging:
+//
+//	r.HandleFunc("/debug/explain", func(w http.ResponseWriter, req *http.Request) {
+//		target, _ := http.NewRequest(req.FormValue("method"), req.FormValue("url"), nil)
+//		fmt.Fprint(w, r.Explain(target))
+//	})
+func (r *Router) Explain(req *http.Request) *MatchTrace {
+	if r.conflicts != nil {
+		r.conflicts.check()
+	}
+	t := &MatchTrace{Routes: r.explain(req)}
+	t.Matched = r.Match(req, &t.Match)
+	return t
+}
+
+func (r *Router) explain(req *http.Request) []RouteTrace {
+	routes, _ := r.routeList()
+	traces := make([]RouteTrace, len(routes))
+	for i, route := range routes {
+		traces[i] = route.explain(req)
+	}
+	return traces
+}
+
+// explain evaluates the matchers of the route like Match does.
+func (r *Route) explain(req *http.Request) RouteTrace {
+	t := RouteTrace{Route: r}
+	switch {
+	case r.err != nil:
+		t.Skipped = r.err.Error()
+	case r.buildOnly:
+		t.Skipped = "build-only"
+	case r.disabled.Load():
+		t.Skipped = "disabled"
+	case r.conflict.Load() != nil:
+		t.Skipped = r.conflict.Load().Error()
+	}
+	if t.Skipped != "" {
+		return t
+	}
+
+	t.Matched = true
+	for _, m := range r.matchers {
+		mt := newMatcherTrace(m)
+		mt.Matched = m.Match(req, &RouteMatch{})
+		if sub, ok := m.(*Router); ok {
+			mt.Routes = sub.explain(req)
+		}
+		t.Matchers = append(t.Matchers, mt)
+		if !mt.Matched {
+			t.Matched = false
+			if mt.Kind == MatcherMethods {
+				continue
+			}
+			break
+		}
+		if rr, ok := m.(*routeRegexp); ok {
+			t.Vars = rr.matchVars(req, t.Vars)
+		}
+	}
+	return t
+}
+
+// newMatcherTrace returns the kind and description of a matcher.
+func newMatcherTrace(m matcher) MatcherTrace {
+	switch m := m.(type) {
+	case *routeRegexp:
+		return MatcherTrace{Kind: m.matcherKind(), Value: m.template}
+	case methodMatcher:
+		return MatcherTrace{Kind: MatcherMethods, Value: strings.Join(m, ", ")}
+	case schemeMatcher:
+		return MatcherTrace{Kind: MatcherSchemes, Value: strings.Join(m, ", ")}
+	case headerMatcher:
+		return MatcherTrace{Kind: MatcherHeaders, Value: headerPairs(m)}
+	case headerRegexMatcher:
+		return MatcherTrace{Kind: MatcherHeaders, Value: headerPairs(headerRegexpStrings(m))}
+	case *Router:
+		return MatcherTrace{Kind: MatcherSubrouter}
+	case MatcherFunc:
+		name := ""
+		if f := runtime.FuncForPC(reflect.ValueOf(m).Pointer()); f != nil {
+			name = f.Name()
+		}
+		return MatcherTrace{Kind: MatcherCustom, Value: name}
+	}
+	return MatcherTrace{Kind: MatcherCustom, Value: fmt.Sprintf("%T", m)}
+}
+
+// matcherKind returns the kind of matcher of the regexp.
+func (r *routeRegexp) matcherKind() MatcherKind {
+	switch r.regexpType {
+	case regexpTypeHost:
+		return MatcherHost
+	case regexpTypePrefix:
+		return MatcherPathPrefix
+	case regexpTypeQuery:
+		return MatcherQuery
+	}
+	return MatcherPath
+}
+
+// headerPairs formats header matcher pairs, sorted by key.
+func headerPairs(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		if v == "" {
+			pairs = append(pairs, k)
+		} else {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// matchVars extracts the variables of a matching host, path or query regexp.
+func (r *routeRegexp) matchVars(req *http.Request, vars map[string]string) map[string]string {
+	if len(r.varsN) == 0 {
+		return vars
+	}
+	var input string
+	switch r.regexpType {
+	case regexpTypeHost:
+		input = getHost(req)
+		if r.wildcardHostPort {
+			if i := strings.Index(input, ":"); i != -1 {
+				input = input[:i]
+			}
+		}
+	case regexpTypeQuery:
+		input = r.getURLQuery(req)
+	default:
+		input = req.URL.Path
+		if r.options.useEncodedPath {
+			input = req.URL.EscapedPath()
+		}
+	}
+	if matches := r.regexp.FindStringSubmatchIndex(input); len(matches) > 0 {
+		vars = extractVars(input, matches, r.varsN, r.defaults, vars)
+	}
+	return vars
+}
+
+// String formats the trace as an indented list of routes and matchers,
+// for logs and test failure messages.
+func (t *MatchTrace) String() string {
+	var b strings.Builder
+	if t.Matched && t.Match.Route != nil {
+		fmt.Fprintf(&b, "matched route %s\n", t.Match.Route.summary())
+	} else if t.Match.MatchErr != nil {
+		fmt.Fprintf(&b, "no match: %v\n", t.Match.MatchErr)
+	} else {
+		b.WriteString("no match\n")
+	}
+	writeRouteTraces(&b, t.Routes, "")
+	return b.String()
+}
+
+func writeRouteTraces(b *strings.Builder, traces []RouteTrace, indent string) {
+	for _, t := range traces {
+		fmt.Fprintf(b, "%sroute %s: ", indent, t.Route.summary())
+		switch {
+		case t.Skipped != "":
+			fmt.Fprintf(b, "skipped (%s)\n", t.Skipped)
+			continue
+		case t.Matched:
+			b.WriteString("match")
+		default:
+			b.WriteString("no match")
+		}
+		if len(t.Vars) > 0 {
+			fmt.Fprintf(b, " %v", t.Vars)
+		}
+		b.WriteString("\n")
+		for _, m := range t.Matchers {
+			fmt.Fprintf(b, "%s  %s", indent, m.Kind)
+			if m.Value != "" {
+				fmt.Fprintf(b, " %q", m.Value)
+			}
+			if m.Matched {
+				b.WriteString(": match\n")
+			} else {
+				b.WriteString(": no match\n")
+			}
+			writeRouteTraces(b, m.Routes, indent+"    ")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/users/{id:[0-9]+}", dummyHandler).Methods(http.MethodPost)
+	r.HandleFunc("/users/{id}", dummyHandler).Headers("X-Version", "2")
+	r.HandleFunc("/users/{id}", dummyHandler).Queries("fields", "{fields}")
+	r.HandleFunc("/users/{id}", dummyHandler).MatcherFunc(func(*http.Request, *RouteMatch) bool { return false })
+	r.HandleFunc("/other", dummyHandler)
+	r.HandleFunc("/users/{id}", dummyHandler).BuildOnly()
+	s := r.Host("{sub}.example.com").Subrouter()
+	s.HandleFunc("/users/{id}", dummyHandler).Schemes("https")
+	r.HandleFunc("/users/{id}", dummyHandler).Name("user")
+
+	trace := r.Explain(newRequest(http.MethodGet, "http://api.example.com/users/42"))
+	if !trace.Matched || trace.Match.Route != r.Get("user") {
+		t.Fatalf("Expected the route named user to match, got %v", trace)
+	}
+	if len(trace.Routes) != 8 {
+		t.Fatalf("Expected 8 route traces, got %d", len(trace.Routes))
+	}
+
+	failed := []struct {
+		kind  MatcherKind
+		value string
+	}{
+		{MatcherMethods, "POST"},
+		{MatcherHeaders, "X-Version=2"},
+		{MatcherQuery, "fields={fields}"},
+		{MatcherCustom, "github.com/gorilla/mux.TestExplain.func1"},
+		{MatcherPath, "/other"},
+	}
+	for i, want := range failed {
+		rt := trace.Routes[i]
+		f := rt.Failed()
+		if rt.Matched || f == nil || f.Kind != want.kind || f.Value != want.value {
+			t.Errorf("Route %d: expected a failed %v matcher %q, got %+v", i, want.kind, want.value, f)
+		}
+	}
+	if got := trace.Routes[0].Vars; !reflect.DeepEqual(got, map[string]string{"id": "42"}) {
+		t.Errorf("Expected the path variables to be extracted, got %v", got)
+	}
+	if got := trace.Routes[5].Skipped; got != "build-only" {
+		t.Errorf("Expected the build-only route to be skipped, got %q", got)
+	}
+
+	sub := trace.Routes[6]
+	if sub.Matched || len(sub.Matchers) != 2 || sub.Matchers[1].Kind != MatcherSubrouter {
+		t.Fatalf("Expected a failed subrouter matcher, got %+v", sub.Matchers)
+	}
+	nested := sub.Matchers[1].Routes
+	if len(nested) != 1 || nested[0].Failed().Kind != MatcherSchemes {
+		t.Fatalf("Expected the nested route to fail on its scheme, got %+v", nested)
+	}
+	want := map[string]string{"sub": "api", "id": "42"}
+	if got := nested[0].Vars; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected nested variables %v, got %v", want, got)
+	}
+
+	s.HandleFunc("/users/{id}", dummyHandler).Methods(http.MethodGet)
+	if rt := r.Explain(newRequest(http.MethodGet, "http://api.example.com/users/42")).Routes[6]; !rt.Matched {
+		t.Errorf("Expected the subrouter route to match, got %+v", rt)
+	}
+}
+
+func TestExplainMethodMismatch(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/a", dummyHandler).Methods(http.MethodPost).Headers("X-A", "")
+	trace := r.Explain(newRequestWithHeaders(http.MethodGet, "http://localhost/a", "X-A", "1"))
+	if trace.Matched || trace.Match.MatchErr != ErrMethodMismatch {
+		t.Fatalf("Expected a method mismatch, got %v", trace)
+	}
+	// Matching carries on after a method mismatch.
+	if m := trace.Routes[0].Matchers; len(m) != 3 || m[1].Matched || !m[2].Matched {
+		t.Errorf("Expected all the matchers to be evaluated, got %+v", m)
+	}
+	s := trace.String()
+	for _, want := range []string{
+		"no match: method is not allowed",
+		`route "POST /a": no match`,
+		`  methods "POST": no match`,
+		`  headers "X-A": match`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Expected trace to contain %q, got:\n%s", want, s)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/http"
+	"time"
+)
+
+// Timeout sets the maximum duration of the requests matching the route. When
+// the router dispatches such a request, it wraps the handler and its
+// middlewares in http.TimeoutHandler, which replies with 503 Service
+// Unavailable and the message set with TimeoutMessage if the handler takes
+// longer. Zero, the default, sets no timeout.
+func (r *Route) Timeout(d time.Duration) *Route {
+	r.timeout = d
+	return r
+}
+
+// TimeoutMessage sets the body of the responses to requests exceeding the
+// route timeout, see Timeout. If empty, http.TimeoutHandler writes a default
+// message.
+func (r *Route) TimeoutMessage(msg string) *Route {
+	r.timeoutMessage = msg
+	return r
+}
+
+// MaxBodyBytes sets the maximum size of the body of the requests matching
+// the route. When the router dispatches such a request, it wraps the body
+// in http.MaxBytesReader, so reading past the limit fails with an
+// *http.MaxBytesError. Zero, the default, sets no limit.
+func (r *Route) MaxBodyBytes(n int64) *Route {
+	r.maxBodyBytes = n
+	return r
+}
+
+// GetTimeout returns the timeout of the route, see Timeout.
+func (r *Route) GetTimeout() time.Duration {
+	return r.timeout
+}
+
+// GetMaxBodyBytes returns the maximum body size of the route, see
+// MaxBodyBytes.
+func (r *Route) GetMaxBodyBytes() int64 {
+	return r.maxBodyBytes
+}
+
+// Timeout sets the timeout of the routes registered afterwards on the router
+// and its subrouters. See Route.Timeout.
+func (r *Router) Timeout(d time.Duration) *Router {
+	r.timeout = d
+	return r
+}
+
+// TimeoutMessage sets the timeout message of the routes registered afterwards
+// on the router and its subrouters. See Route.TimeoutMessage.
+func (r *Router) TimeoutMessage(msg string) *Router {
+	r.timeoutMessage = msg
+	return r
+}
+
+// MaxBodyBytes sets the maximum body size of the routes registered afterwards
+// on the router and its subrouters. See Route.MaxBodyBytes.
+func (r *Router) MaxBodyBytes(n int64) *Router {
+	r.maxBodyBytes = n
+	return r
+}
+
+// limitHandler wraps the handler of a matched route in the route limits.
+func (r *Route) limitHandler(h http.Handler) http.Handler {
+	if n := r.maxBodyBytes; n > 0 {
+		next := h
+		h = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Body != nil {
+				req.Body = http.MaxBytesReader(w, req.Body, n)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	if r.timeout > 0 {
+		h = http.TimeoutHandler(h, r.timeout, r.timeoutMessage)
+	}
+	return h
+}
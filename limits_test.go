@@ -0,0 +1,95 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}
+
+	router := NewRouter()
+	router.HandleFunc("/slow", slow).Timeout(10 * time.Millisecond).TimeoutMessage("too slow")
+	router.HandleFunc("/fast", stringHandler("fast")).Timeout(time.Second)
+	api := router.PathPrefix("/api").Subrouter().Timeout(10 * time.Millisecond)
+	api.HandleFunc("/report", slow)
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/slow", http.StatusServiceUnavailable, "too slow"},
+		{"/fast", http.StatusOK, "fast"},
+		{"/api/report", http.StatusServiceUnavailable, "<html><head><title>Timeout</title></head><body><h1>Timeout</h1></body></html>"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newRequest(http.MethodGet, tt.path))
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.path, tt.code, tt.body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRouteMaxBodyBytes(t *testing.T) {
+	read := func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		_, _ = w.Write(b)
+	}
+
+	router := NewRouter().MaxBodyBytes(8)
+	router.HandleFunc("/small", read)
+	router.HandleFunc("/large", read).MaxBodyBytes(1024)
+
+	tests := []struct {
+		path string
+		body string
+		code int
+	}{
+		{"/small", "12345678", http.StatusOK},
+		{"/small", "123456789", http.StatusRequestEntityTooLarge},
+		{"/large", "123456789", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost"+tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s %q: expected status code %d, got %d", tt.path, tt.body, tt.code, w.Code)
+		}
+	}
+}
+
+func TestRouteLimitsIntrospection(t *testing.T) {
+	router := NewRouter().Timeout(time.Second)
+	route := router.HandleFunc("/upload", dummyHandler).MaxBodyBytes(1 << 20)
+	if route.GetTimeout() != time.Second || route.GetMaxBodyBytes() != 1<<20 {
+		t.Errorf("Unexpected limits %v, %d", route.GetTimeout(), route.GetMaxBodyBytes())
+	}
+	routes := router.Routes()
+	if len(routes) != 1 || routes[0].Timeout != time.Second || routes[0].MaxBodyBytes != 1<<20 {
+		t.Errorf("Unexpected routes %+v", routes)
+	}
+}
@@ -1,6 +1,7 @@
 package mux
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 )
@@ -21,7 +22,12 @@ func (mw MiddlewareFunc) Middleware(handler http.Handler) http.Handler {
 }
 
 // Use appends a MiddlewareFunc to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Router.
+//
+// Use panics if the router is frozen, see Router.Freeze.
 func (r *Router) Use(mwf ...MiddlewareFunc) {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
 	for _, fn := range mwf {
 		r.middlewares = append(r.middlewares, fn)
 	}
@@ -29,13 +35,257 @@ func (r *Router) Use(mwf ...MiddlewareFunc) {
 
 // useInterface appends a middleware to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Router.
 func (r *Router) useInterface(mw middleware) {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
 	r.middlewares = append(r.middlewares, mw)
 }
 
+// namedMiddleware is a middleware registered with a name, see Router.UseNamed.
+type namedMiddleware struct {
+	name string
+	middleware
+}
+
+// middlewareName returns the name of a middleware, or "" if it has none.
+func middlewareName(mw middleware) string {
+	if nm, ok := mw.(namedMiddleware); ok {
+		return nm.name
+	}
+	return ""
+}
+
+// replaceMiddleware replaces the middlewares with the given name in chain,
+// and reports whether there were any.
+func replaceMiddleware(chain []middleware, name string, mwf MiddlewareFunc) bool {
+	found := false
+	for i, mw := range chain {
+		if middlewareName(mw) == name {
+			chain[i] = namedMiddleware{name, mwf}
+			found = true
+		}
+	}
+	return found
+}
+
+// setMiddlewareOverride returns overrides with the middleware used instead
+// of the inherited middlewares with the given name, nil to skip them.
+func setMiddlewareOverride(overrides map[string]middleware, name string, mw middleware) map[string]middleware {
+	if overrides == nil {
+		overrides = make(map[string]middleware)
+	}
+	overrides[name] = mw
+	return overrides
+}
+
+// UseNamed appends a MiddlewareFunc to the chain like Use, with a name that
+// subrouters and routes can refer to with Without and Replace.
+//
+// UseNamed panics if the router is frozen, see Router.Freeze.
+func (r *Router) UseNamed(name string, mwf MiddlewareFunc) {
+	r.useInterface(namedMiddleware{name, mwf})
+}
+
+// Without skips the middlewares with the given names, registered with
+// UseNamed on the routers above the router, for the requests matching its
+// routes. The middlewares of the router itself are not affected.
+//
+// Without panics if the router is frozen, see Router.Freeze.
+func (r *Router) Without(names ...string) *Router {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
+	for _, name := range names {
+		r.middlewareOverrides = setMiddlewareOverride(r.middlewareOverrides, name, nil)
+	}
+	return r
+}
+
+// Replace replaces the middlewares registered with the given name. If the
+// router has such middlewares, they are replaced in place. Otherwise, mwf is
+// used instead of those of the routers above the router, for the requests
+// matching its routes.
+//
+// Replace panics if the router is frozen, see Router.Freeze.
+func (r *Router) Replace(name string, mwf MiddlewareFunc) *Router {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
+	if !replaceMiddleware(r.middlewares, name, mwf) {
+		r.middlewareOverrides = setMiddlewareOverride(r.middlewareOverrides, name, namedMiddleware{name, mwf})
+	}
+	return r
+}
+
+// ErrRouteNotInRouter is returned by Router.MiddlewareChain for a route that
+// is not registered on the router or its subrouters.
+var ErrRouteNotInRouter = errors.New("mux: route not found in router")
+
+// MiddlewareChain returns the names of the middlewares wrapping the handler
+// of a route of the router or its subrouters, outermost first: those of the
+// routers above the route, with Without and Replace applied, followed by
+// those of the route, as composed by GetHandlerWithMiddlewares. Middlewares
+// added with Use have an empty name.
+func (r *Router) MiddlewareChain(route *Route) ([]string, error) {
+	var names []string
+	found := false
+	owner := make(map[*Route]*Router)
+	err := r.Walk(func(rt *Route, router *Router, ancestors []*Route) error {
+		owner[rt] = router
+		if rt != route {
+			return nil
+		}
+		for _, mw := range middlewareChain(rt, router, ancestors, owner) {
+			names = append(names, middlewareName(mw))
+		}
+		found = true
+		return errStopWalk
+	})
+	if err != nil && err != errStopWalk {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrRouteNotInRouter
+	}
+	return names, nil
+}
+
+// errStopWalk stops a walk once the route looked for is found.
+var errStopWalk = errors.New("stop walk")
+
+// middlewareChain returns the middlewares wrapping the handler of a route,
+// outermost first, given the route ancestors and the router owning each of
+// them.
+func middlewareChain(route *Route, router *Router, ancestors []*Route, owner map[*Route]*Router) []middleware {
+	// The chain is built from the innermost middlewares, like matchRoute.
+	var overrides RouteMatch
+	outer := [][]middleware{route.middlewares}
+	overrides.addMiddlewareOverrides(route.middlewareOverrides)
+	outer = append(outer, overrides.routerMiddlewares(router))
+	overrides.addMiddlewareOverrides(router.middlewareOverrides)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		a := ancestors[i]
+		if router == a.handler {
+			// A router used as handler is served on its own, wrapped in the
+			// route middlewares.
+			overrides = RouteMatch{}
+			outer = append(outer, a.middlewares)
+		}
+		overrides.addMiddlewareOverrides(a.middlewareOverrides)
+		router = owner[a]
+		outer = append(outer, overrides.routerMiddlewares(router))
+		overrides.addMiddlewareOverrides(router.middlewareOverrides)
+	}
+	var chain []middleware
+	for i := len(outer) - 1; i >= 0; i-- {
+		chain = append(chain, outer[i]...)
+	}
+	return chain
+}
+
+// addMiddlewareOverrides adds the middleware overrides of a route or router,
+// see Router.Without and Router.Replace, unless the match already has
+// overrides for the same names from a deeper route or router. The map is
+// copied so that routes failing to match leave the overrides unchanged.
+func (m *RouteMatch) addMiddlewareOverrides(overrides map[string]middleware) {
+	if len(overrides) == 0 {
+		return
+	}
+	merged := make(map[string]middleware, len(m.middlewareOverrides)+len(overrides))
+	for name, mw := range overrides {
+		merged[name] = mw
+	}
+	for name, mw := range m.middlewareOverrides {
+		merged[name] = mw
+	}
+	m.middlewareOverrides = merged
+}
+
+// routerMiddlewares returns the middlewares of a router with the overrides of
+// the match applied.
+func (m *RouteMatch) routerMiddlewares(r *Router) []middleware {
+	if len(m.middlewareOverrides) == 0 {
+		return r.middlewares
+	}
+	mws := make([]middleware, 0, len(r.middlewares))
+	for _, mw := range r.middlewares {
+		if name := middlewareName(mw); name != "" {
+			if o, ok := m.middlewareOverrides[name]; ok {
+				mw = o
+			}
+		}
+		if mw != nil {
+			mws = append(mws, mw)
+		}
+	}
+	return mws
+}
+
+// unmatchedHandler wraps the handler of an unmatched request in the
+// middlewares of the routers partially matching it, with the overrides of
+// these routers and of the routes above them applied like for a matching
+// route. Unless all is true, only the routers using their middlewares for
+// unmatched requests are included, see Router.UseForUnmatched. The overrides
+// are left in the match for the routers above the chain.
+func (m *RouteMatch) unmatchedHandler(h http.Handler, all bool) http.Handler {
+	var overrides RouteMatch
+	for i := len(m.unmatched) - 1; i >= 0; i-- {
+		u := m.unmatched[i]
+		if all || u.router.useForUnmatched {
+			mws := overrides.routerMiddlewares(u.router)
+			for j := len(mws) - 1; j >= 0; j-- {
+				h = mws[j].Middleware(h)
+			}
+		}
+		overrides.addMiddlewareOverrides(u.router.middlewareOverrides)
+		if u.route != nil {
+			overrides.addMiddlewareOverrides(u.route.middlewareOverrides)
+		}
+	}
+	m.middlewareOverrides = overrides.middlewareOverrides
+	return h
+}
+
+// OnRequest appends a hook called by ServeHTTP for every request before
+// matching, in the order the hooks were added. The request a hook returns,
+// if not nil, replaces the request for the next hooks, for matching and for
+// the handler, so hooks can rewrite or normalize the URL that routes match.
+// Path cleaning, see Router.SkipClean, applies to the rewritten path.
+//
+// Hooks of subrouters are not called. OnRequest panics if the router is
+// frozen, see Router.Freeze.
+func (r *Router) OnRequest(hook func(*http.Request) *http.Request) {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
+	r.onRequest = append(r.onRequest, hook)
+}
+
+// OnMatch appends a hook called by ServeHTTP for every request after matching
+// and before calling the handler, in the order the hooks were added. The
+// match holds the matched route, its variables and its handler wrapped in
+// the middlewares, or the match error if no route matched. Hooks may modify
+// the match: for example, setting Handler serves the request with another
+// handler.
+//
+// Hooks of subrouters are not called. OnMatch panics if the router is
+// frozen, see Router.Freeze.
+func (r *Router) OnMatch(hook func(*http.Request, *RouteMatch)) {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
+	r.onMatch = append(r.onMatch, hook)
+}
+
 // RouteMiddleware -------------------------------------------------------------
 
 // Use appends a MiddlewareFunc to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Route. Route middleware are executed after the Router middleware but before the Route handler.
+//
+// Use panics if the router of the route is frozen, see Router.Freeze.
 func (r *Route) Use(mwf ...MiddlewareFunc) *Route {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
 	for _, fn := range mwf {
 		r.middlewares = append(r.middlewares, fn)
 	}
@@ -45,9 +295,52 @@ func (r *Route) Use(mwf ...MiddlewareFunc) *Route {
 
 // useInterface appends a MiddlewareFunc to the chain. Middleware can be used to intercept or otherwise modify requests and/or responses, and are executed in the order that they are applied to the Route. Route middleware are executed after the Router middleware but before the Route handler.
 func (r *Route) useInterface(mw middleware) {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
 	r.middlewares = append(r.middlewares, mw)
 }
 
+// UseNamed appends a MiddlewareFunc to the chain like Use, with a name that
+// can be referred to with Replace.
+//
+// UseNamed panics if the router of the route is frozen, see Router.Freeze.
+func (r *Route) UseNamed(name string, mwf MiddlewareFunc) *Route {
+	r.useInterface(namedMiddleware{name, mwf})
+	return r
+}
+
+// Without skips the middlewares with the given names, registered with
+// UseNamed on the routers above the route, for the requests matching the
+// route, or the routes of its subrouter.
+//
+// Without panics if the router of the route is frozen, see Router.Freeze.
+func (r *Route) Without(names ...string) *Route {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
+	for _, name := range names {
+		r.middlewareOverrides = setMiddlewareOverride(r.middlewareOverrides, name, nil)
+	}
+	return r
+}
+
+// Replace replaces the middlewares registered with the given name. If the
+// route has such middlewares, they are replaced in place. Otherwise, mwf is
+// used instead of those of the routers above the route, for the requests
+// matching the route, or the routes of its subrouter.
+//
+// Replace panics if the router of the route is frozen, see Router.Freeze.
+func (r *Route) Replace(name string, mwf MiddlewareFunc) *Route {
+	if r.frozen {
+		panic(ErrRouterFrozen)
+	}
+	if !replaceMiddleware(r.middlewares, name, mwf) {
+		r.middlewareOverrides = setMiddlewareOverride(r.middlewareOverrides, name, namedMiddleware{name, mwf})
+	}
+	return r
+}
+
 // CORSMethodMiddleware automatically sets the Access-Control-Allow-Methods response header
 // on requests for routes that have an OPTIONS method matcher to all the method matchers on
 // the route. Routes that do not explicitly handle OPTIONS requests will not be processed
@@ -74,7 +367,8 @@ func CORSMethodMiddleware(r *Router) MiddlewareFunc {
 func getAllMethodsForRoute(r *Router, req *http.Request) ([]string, error) {
 	var allMethods []string
 
-	for _, route := range r.routes {
+	routes, _ := r.routeList()
+	for _, route := range routes {
 		var match RouteMatch
 		if route.Match(req, &match) || match.MatchErr == ErrMethodMismatch {
 			methods, err := route.GetMethods()
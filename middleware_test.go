@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -675,3 +678,256 @@ func TestMiddlewareOnMultiSubrouter(t *testing.T) {
 		}
 	})
 }
+
+func TestMiddlewareUseForUnmatched(t *testing.T) {
+	writer := func(s string) MiddlewareFunc {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(s))
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter().UseForUnmatched(true)
+	router.Use(writer("root "))
+	router.HandleFunc("/", dummyHandler).Methods(http.MethodGet)
+	api := router.PathPrefix("/api").Subrouter()
+	api.Use(writer("api "))
+	api.HandleFunc("/items", dummyHandler).Methods(http.MethodGet)
+	v2 := api.PathPrefix("/v2").Subrouter()
+	v2.Use(writer("v2 "))
+	v2.HandleFunc("/items", dummyHandler).Methods(http.MethodGet)
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(writer("admin "))
+	admin.NotFoundHandler = stringHandler("admin not found")
+	router.HandleFunc("/api/other", dummyHandler).Methods(http.MethodGet)
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/missing", "root 404 page not found\n"},
+		{http.MethodPost, "/", "root "},
+		{http.MethodGet, "/api/missing", "root api 404 page not found\n"},
+		{http.MethodPost, "/api/items", "root api "},
+		{http.MethodGet, "/api/v2/missing", "root api v2 404 page not found\n"},
+		{http.MethodGet, "/admin/missing", "root admin admin not found"},
+		// A sibling route matching after the subrouter clears the chain.
+		{http.MethodGet, "/api/other", "root "},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newRequest(tt.method, tt.path))
+		if w.Body.String() != tt.want {
+			t.Errorf("%s %s: expected %q, got %q", tt.method, tt.path, tt.want, w.Body.String())
+		}
+	}
+
+	// Disabled by default.
+	router = NewRouter()
+	router.Use(writer("root "))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodGet, "/missing"))
+	if w.Body.String() != "404 page not found\n" {
+		t.Errorf("Expected the middleware not to run, got %q", w.Body.String())
+	}
+}
+
+func TestMiddlewareUseForUnmatchedOverrides(t *testing.T) {
+	writer := func(s string) MiddlewareFunc {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(s))
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter().UseForUnmatched(true)
+	router.UseNamed("auth", writer("auth "))
+	router.UseNamed("log", writer("log "))
+	router.HandleFunc("/private", dummyHandler).Methods(http.MethodGet)
+	public := router.PathPrefix("/public").Subrouter().Without("auth")
+	public.HandleFunc("/x", dummyHandler).Methods(http.MethodGet)
+	legacy := router.PathPrefix("/legacy").Replace("log", writer("legacy ")).Subrouter()
+	legacy.HandleFunc("/x", dummyHandler).Methods(http.MethodGet)
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPost, "/private", "auth log "},
+		{http.MethodPost, "/public/x", "log "},
+		{http.MethodGet, "/public/missing", "log 404 page not found\n"},
+		{http.MethodPost, "/legacy/x", "auth legacy "},
+		{http.MethodGet, "/missing", "auth log 404 page not found\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newRequest(tt.method, tt.path))
+		if w.Body.String() != tt.want {
+			t.Errorf("%s %s: expected %q, got %q", tt.method, tt.path, tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestRouterHooks(t *testing.T) {
+	var calls []string
+	router := NewRouter()
+	router.Use(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "middleware")
+			h.ServeHTTP(w, r)
+		})
+	})
+	router.OnRequest(func(r *http.Request) *http.Request {
+		calls = append(calls, "request "+r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/v1/") {
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = strings.TrimPrefix(r.URL.Path, "/v1")
+			return r2
+		}
+		return nil
+	})
+	router.OnRequest(func(r *http.Request) *http.Request {
+		calls = append(calls, "request "+r.URL.Path)
+		return r
+	})
+	router.OnMatch(func(r *http.Request, match *RouteMatch) {
+		switch {
+		case match.MatchErr != nil:
+			calls = append(calls, "match error "+match.MatchErr.Error())
+		default:
+			calls = append(calls, "match "+getRouteTemplate(match.Route)+" "+match.Vars["id"])
+		}
+	})
+	router.OnMatch(func(r *http.Request, match *RouteMatch) {
+		if r.URL.Path == "/teapot" {
+			match.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+		}
+	})
+	router.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler "+Vars(r)["id"])
+	}).Methods(http.MethodGet)
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		calls  []string
+	}{
+		{http.MethodGet, "/items/1", http.StatusOK, []string{
+			"request /items/1", "request /items/1", "match Host: none, Path: /items/{id} 1", "middleware", "handler 1",
+		}},
+		{http.MethodGet, "/v1/items/2", http.StatusOK, []string{
+			"request /v1/items/2", "request /items/2", "match Host: none, Path: /items/{id} 2", "middleware", "handler 2",
+		}},
+		{http.MethodPost, "/items/3", http.StatusMethodNotAllowed, []string{
+			"request /items/3", "request /items/3", "match error method is not allowed",
+		}},
+		{http.MethodGet, "/teapot", http.StatusTeapot, []string{
+			"request /teapot", "request /teapot", "match error no matching route was found",
+		}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newRequest(tt.method, tt.path))
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected status code %d, got %d", tt.method, tt.path, tt.code, w.Code)
+		}
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("%s %s: expected calls %q, got %q", tt.method, tt.path, tt.calls, calls)
+		}
+	}
+}
+
+func TestNamedMiddleware(t *testing.T) {
+	var calls []string
+	named := func(s string) MiddlewareFunc {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, s)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter()
+	router.UseNamed("logging", named("logging"))
+	router.UseNamed("auth", named("auth"))
+	router.Use(named("unnamed"))
+	router.HandleFunc("/users", dummyHandler)
+	health := router.HandleFunc("/health", dummyHandler).Without("auth")
+	api := router.PathPrefix("/api").Subrouter()
+	api.UseNamed("api", named("api"))
+	api.HandleFunc("/items", dummyHandler)
+	public := api.PathPrefix("/public").Subrouter().Without("auth", "logging")
+	public.HandleFunc("/status", dummyHandler)
+	test := router.PathPrefix("/test").Subrouter().Replace("auth", named("fake auth"))
+	test.HandleFunc("/items", dummyHandler).UseNamed("route", named("route"))
+	overridden := test.HandleFunc("/override", dummyHandler).Replace("auth", named("route auth"))
+
+	tests := []struct {
+		path  string
+		calls []string
+	}{
+		{"/users", []string{"logging", "auth", "unnamed"}},
+		{"/health", []string{"logging", "unnamed"}},
+		{"/api/items", []string{"logging", "auth", "unnamed", "api"}},
+		{"/api/public/status", []string{"unnamed", "api"}},
+		{"/test/items", []string{"logging", "fake auth", "unnamed", "route"}},
+		{"/test/override", []string{"logging", "route auth", "unnamed"}},
+		// A route failing to match doesn't leave its overrides.
+		{"/users", []string{"logging", "auth", "unnamed"}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		router.ServeHTTP(NewRecorder(), newRequest(http.MethodGet, tt.path))
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("%s: expected middlewares %q, got %q", tt.path, tt.calls, calls)
+		}
+	}
+
+	// Replacing a middleware of the router itself.
+	router.Replace("logging", named("other logging"))
+	calls = nil
+	router.ServeHTTP(NewRecorder(), newRequest(http.MethodGet, "/users"))
+	if want := []string{"other logging", "auth", "unnamed"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected middlewares %q, got %q", want, calls)
+	}
+
+	var statusRoute, itemsRoute *Route
+	_ = router.Walk(func(route *Route, _ *Router, _ []*Route) error {
+		tpl, _ := route.GetPathTemplate()
+		switch tpl {
+		case "/api/public/status":
+			statusRoute = route
+		case "/test/items":
+			itemsRoute = route
+		}
+		return nil
+	})
+	for _, tt := range []struct {
+		route *Route
+		names []string
+	}{
+		{health, []string{"logging", ""}},
+		{statusRoute, []string{"", "api"}},
+		{itemsRoute, []string{"logging", "auth", "", "route"}},
+		{overridden, []string{"logging", "auth", ""}},
+	} {
+		names, err := router.MiddlewareChain(tt.route)
+		if err != nil || !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("%s: expected chain %q, got %q, %v", tt.route.summary(), tt.names, names, err)
+		}
+	}
+	if _, err := router.MiddlewareChain(NewRouter().NewRoute()); err != ErrRouteNotInRouter {
+		t.Errorf("Expected ErrRouteNotInRouter, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mount registers a new route serving a handler for a path prefix and
+// everything below it, such as a file server or an application built with
+// another framework. The prefix is a template like in PathPrefix, and may
+// contain variables:
+//
+//	r.Mount("/t/{tenant}/admin", adminHandler)
+//
+// The route matches the paths equal to the prefix or continuing after it
+// with a slash, such as "/t/acme/admin" and "/t/acme/admin/users" but not
+// "/t/acme/administrator". The handler is called with the matched prefix,
+// including the path prefixes of the parent routes, stripped from both
+// URL.Path and URL.RawPath, leaving a path starting with a slash. The route
+// variables are still available with Vars.
+//
+// See Route.IsMount and Route.MountedURL.
+func (r *Router) Mount(tpl string, handler http.Handler) *Route {
+	route := r.NewRoute().PathPrefix(strings.TrimSuffix(tpl, "/"))
+	if route.err != nil {
+		return route
+	}
+	route.mount = true
+	route.addMatcher(mountMatcher{route})
+	return route.Handler(route.mountHandler(handler))
+}
+
+// IsMount returns true if the route was registered with Router.Mount.
+func (r *Route) IsMount() bool {
+	return r.mount
+}
+
+// errNotMount is returned by MountedURL for routes not registered with Mount.
+var errNotMount = errors.New("mux: route is not a mount")
+
+// MountedURL builds the URL of a path served by the handler of a route
+// registered with Router.Mount, given the path as seen by the handler and
+// the variables of the prefix. For example:
+//
+//	r.Mount("/t/{tenant}/admin", adminHandler).Name("admin")
+//	url, err := r.Get("admin").MountedURL("/users/42", "tenant", "acme")
+//	// "/t/acme/admin/users/42"
+func (r *Route) MountedURL(path string, pairs ...string) (*url.URL, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !r.mount {
+		return nil, errNotMount
+	}
+	u, err := r.URL(pairs...)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	escaped := strings.TrimSuffix(u.EscapedPath(), "/") + (&url.URL{Path: path}).EscapedPath()
+	if u.Path, err = url.PathUnescape(escaped); err != nil {
+		return nil, err
+	}
+	u.RawPath = ""
+	if u.EscapedPath() != escaped {
+		u.RawPath = escaped
+	}
+	return u, nil
+}
+
+// mountMatcher matches the requests whose path continues after the prefix
+// of a mount with a slash or nothing.
+type mountMatcher struct {
+	route *Route
+}
+
+func (m mountMatcher) Match(req *http.Request, match *RouteMatch) bool {
+	_, _, ok := m.route.mountPaths(req)
+	return ok
+}
+
+// mountPaths returns the path and raw path of a request with the prefix of
+// a mount stripped, and whether the prefix matched.
+func (r *Route) mountPaths(req *http.Request) (path, rawPath string, ok bool) {
+	rr := r.regexp.path
+	escaped := req.URL.EscapedPath()
+	if rr.options.useEncodedPath {
+		loc := rr.regexp.FindStringIndex(escaped)
+		if loc == nil {
+			return "", "", false
+		}
+		rawPath = escaped[loc[1]:]
+		p, err := url.PathUnescape(rawPath)
+		if err != nil {
+			return "", "", false
+		}
+		path = p
+	} else {
+		loc := rr.regexp.FindStringIndex(req.URL.Path)
+		if loc == nil {
+			return "", "", false
+		}
+		path = req.URL.Path[loc[1]:]
+		rawPath = escaped[escapedPrefixLen(escaped, loc[1]):]
+	}
+	if path != "" && path[0] != '/' {
+		return "", "", false
+	}
+	if path == "" {
+		path, rawPath = "/", "/"
+	}
+	if (&url.URL{Path: path}).EscapedPath() == rawPath {
+		rawPath = ""
+	}
+	return path, rawPath, true
+}
+
+// escapedPrefixLen returns the length of the prefix of an escaped path that
+// decodes to n bytes.
+func escapedPrefixLen(escaped string, n int) int {
+	i := 0
+	for ; n > 0 && i < len(escaped); n-- {
+		if escaped[i] == '%' && i+2 < len(escaped) {
+			i += 3
+		} else {
+			i++
+		}
+	}
+	return i
+}
+
+// mountHandler strips the prefix of a mount from the requests passed to h.
+func (r *Route) mountHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, rawPath, ok := r.mountPaths(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		r2 := req.WithContext(req.Context())
+		u := *req.URL
+		u.Path, u.RawPath = path, rawPath
+		r2.URL = &u
+		h.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", r.URL.Path, r.URL.RawPath, Vars(r)["tenant"])
+	})
+
+	router := NewRouter()
+	router.Mount("/static/", echo)
+	router.Mount("/t/{tenant}/admin", echo)
+	api := router.PathPrefix("/api").Subrouter()
+	api.Mount("/legacy", echo)
+	encoded := NewRouter().UseEncodedPath()
+	encoded.Mount("/t/{tenant}/files", echo)
+
+	tests := []struct {
+		router *Router
+		url    string
+		body   string
+	}{
+		{router, "http://localhost/static/css/site.css", "/css/site.css  "},
+		{router, "http://localhost/static", "/  "},
+		{router, "http://localhost/t/acme/admin/users/1", "/users/1  acme"},
+		{router, "http://localhost/t/acme/admin", "/  acme"},
+		{router, "http://localhost/t/acme/admin/x%2Fy", "/x/y /x%2Fy acme"},
+		{router, "http://localhost/api/legacy/items", "/items  "},
+		{encoded, "http://localhost/t/a%2Fb/files/x%2Fy", "/x/y /x%2Fy a%2Fb"},
+		{router, "http://localhost/t/acme/administrator", "404 page not found\n"},
+		{router, "http://localhost/staticfiles", "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.router.ServeHTTP(w, newRequest(http.MethodGet, tt.url))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %q", tt.url, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestMountedURL(t *testing.T) {
+	router := NewRouter()
+	admin := router.Mount("/t/{tenant}/admin/", http.NotFoundHandler())
+	if !admin.IsMount() || router.NewRoute().IsMount() {
+		t.Errorf("Unexpected IsMount results")
+	}
+
+	tests := []struct {
+		path  string
+		pairs []string
+		url   string
+	}{
+		{"/users/42", []string{"tenant", "acme"}, "/t/acme/admin/users/42"},
+		{"users", []string{"tenant", "acme"}, "/t/acme/admin/users"},
+		{"/", []string{"tenant", "a b"}, "/t/a%20b/admin/"},
+		{"/files/a%2Fb", []string{"tenant", "acme"}, "/t/acme/admin/files/a%252Fb"},
+	}
+	for _, tt := range tests {
+		u, err := admin.MountedURL(tt.path, tt.pairs...)
+		if err != nil || u.String() != tt.url {
+			t.Errorf("%s: expected %q, got %v, %v", tt.path, tt.url, u, err)
+		}
+	}
+
+	if _, err := admin.MountedURL("/users"); err == nil {
+		t.Error("Expected an error for a missing variable")
+	}
+	if _, err := router.Path("/items").MountedURL("/x"); err != errNotMount {
+		t.Errorf("Expected errNotMount, got %v", err)
+	}
+
+	routes := router.Routes()
+	if len(routes) != 3 || !routes[0].Mount {
+		t.Errorf("Expected the mount in the routes, got %+v", routes)
+	}
+}
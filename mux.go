@@ -12,6 +12,9 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
 )
 
 var (
@@ -26,6 +29,12 @@ var (
 	RegexpCompileFunc = regexp.Compile
 	// ErrMetadataKeyNotFound is returned when the specified metadata key is not present in the map
 	ErrMetadataKeyNotFound = errors.New("key not found in metadata")
+	// namedRoutesMu guards the maps of named routes, which are shared between
+	// a router and its subrouters.
+	namedRoutesMu sync.RWMutex
+	// ErrRouterFrozen is returned when a route is registered on a router
+	// after Router.Freeze was called.
+	ErrRouterFrozen = errors.New("mux: router is frozen")
 )
 
 // NewRouter returns a new router instance.
@@ -60,9 +69,16 @@ type Router struct {
 	// This can be used to render your own 405 Method Not Allowed errors.
 	MethodNotAllowedHandler http.Handler
 
-	// Routes to be matched, in order.
+	// Routes to be matched, in order. The slice is replaced rather than
+	// modified when routes are removed, see Router.routeList.
 	routes []*Route
 
+	// Guards routes and gen.
+	mu sync.RWMutex
+
+	// Incremented every time routes are added or removed.
+	gen uint64
+
 	// Routes by name for URL building.
 	namedRoutes map[string]*Route
 
@@ -74,6 +90,21 @@ type Router struct {
 	// Slice of middlewares to be called after a match is found
 	middlewares []middleware
 
+	// Middlewares used instead of the inherited middlewares with the same
+	// name, nil to skip them. See Router.Without and Router.Replace.
+	middlewareOverrides map[string]middleware
+
+	// Hooks called by ServeHTTP before and after matching, see
+	// Router.OnRequest and Router.OnMatch.
+	onRequest []func(*http.Request) *http.Request
+	onMatch   []func(*http.Request, *RouteMatch)
+
+	// Index over the route paths, used when radix matching is enabled.
+	index *routeIndexCache
+
+	// If true, no routes or middlewares can be added, see Router.Freeze.
+	frozen bool
+
 	// configuration shared with `Route`
 	routeConf
 }
@@ -97,6 +128,47 @@ type routeConf struct {
 	// if true, the the http.Request context will not contain the router
 	omitRouterFromContext bool
 
+	// If true, routes are looked up in a radix tree of their paths before
+	// being matched.
+	radixMatching bool
+
+	// If true, path templates can have optional parts in brackets and
+	// variables default values, see Router.OptionalParts.
+	optionalParts bool
+
+	// If true, routes conflicting with earlier routes get an error, see
+	// Router.RejectConflicts.
+	rejectConflicts bool
+
+	// The conflict check of the router tree, shared with subrouters. Nil
+	// unless RejectConflicts was called.
+	conflicts *conflictCheck
+
+	// If true, OPTIONS requests matching routes except for their method
+	// are answered with the allowed methods.
+	autoOptions bool
+
+	// If true, HEAD requests are served by the routes matching GET requests.
+	autoHead bool
+
+	// If true, the handlers of unmatched requests are wrapped in the router
+	// middlewares.
+	useForUnmatched bool
+
+	// If true, the route variables and template are also set as the path
+	// values and pattern of the http.Request, see Router.PopulatePathValues.
+	populatePathValues bool
+
+	// Limits applied when dispatching requests to the route, see
+	// Route.Timeout and Route.MaxBodyBytes.
+	timeout        time.Duration
+	timeoutMessage string
+	maxBodyBytes   int64
+
+	// Named patterns that can be used in templates as {name:@pattern}.
+	// Never modified once set, see Router.RegisterPattern.
+	patterns map[string]string
+
 	// Manager for the variables from host and path.
 	regexp routeRegexpGroup
 
@@ -149,24 +221,55 @@ func copyRouteRegexp(r *routeRegexp) *routeRegexp {
 // (eg: not found) has a registered handler, the handler is assigned to the Handler
 // field of the match argument.
 func (r *Router) Match(req *http.Request, match *RouteMatch) bool {
-	for _, route := range r.routes {
-		if route.Match(req, match) {
-			// Build middleware chain if no error was found
-			if match.MatchErr == nil {
-				for i := len(r.middlewares) - 1; i >= 0; i-- {
-					match.Handler = r.middlewares[i].Middleware(match.Handler)
-				}
+	if r.conflicts != nil {
+		r.conflicts.check()
+	}
+	match.depth++
+	defer func() { match.depth-- }()
+	// The deepest chain of routers recorded by earlier routers at the same
+	// level, see unmatchedChain.
+	outer := match.unmatched
+	match.unmatched = nil
+
+	routes, gen := r.routeList()
+	if r.radixMatching && r.index != nil {
+		idx := r.index.get(routes, gen)
+		prev := -1
+		for _, i := range idx.lookup(req) {
+			if match.MatchErr == ErrNotFound && idx.skipClears(prev, i) {
+				// The skipped routes would have failed on their path and
+				// cleared the error, see Route.Match.
+				match.MatchErr = nil
+			}
+			prev = i
+			if r.matchRoute(routes[i], req, match) {
+				return true
+			}
+		}
+	} else {
+		for _, route := range routes {
+			if r.matchRoute(route, req, match) {
+				return true
 			}
-			return true
 		}
 	}
 
 	if match.MatchErr == ErrMethodMismatch {
 		if r.MethodNotAllowedHandler != nil {
+			r.unmatchedChain(match, nil)
+			if r.matchAutoMethod(req, match) {
+				return true
+			}
 			match.Handler = r.MethodNotAllowedHandler
 			return true
 		}
 
+		r.unmatchedChain(match, outer)
+		// Routes of the routers above a subrouter may still match the
+		// request, so only the top-level router answers it on its own.
+		if match.depth == 1 && r.matchAutoMethod(req, match) {
+			return true
+		}
 		return false
 	}
 
@@ -174,18 +277,138 @@ func (r *Router) Match(req *http.Request, match *RouteMatch) bool {
 	if r.NotFoundHandler != nil {
 		match.Handler = r.NotFoundHandler
 		match.MatchErr = ErrNotFound
+		r.unmatchedChain(match, nil)
 		return true
 	}
 
 	match.MatchErr = ErrNotFound
+	r.unmatchedChain(match, outer)
+	return false
+}
+
+// unmatchedChain records the router as partially matching the request, in
+// front of the deepest chain of its subrouters. The chain of earlier routers
+// at the same level is kept instead if it is deeper.
+func (r *Router) unmatchedChain(match *RouteMatch, outer []unmatchedRouter) {
+	if len(match.unmatched) == 0 || match.unmatched[0].router != r {
+		match.unmatched = append([]unmatchedRouter{{router: r}}, match.unmatched...)
+	}
+	if len(outer) >= len(match.unmatched) {
+		match.unmatched = outer
+	}
+}
+
+// matchRoute matches a route of the router and wraps the handler of a
+// successful match in the router middlewares.
+func (r *Router) matchRoute(route *Route, req *http.Request, match *RouteMatch) bool {
+	overrides := match.middlewareOverrides
+	matched := route.Match(req, match)
+	if len(match.unmatched) > 0 && match.unmatched[0].route == nil && match.unmatched[0].router != r {
+		// The chain was just recorded by the subrouter of the route.
+		match.unmatched[0].route = route
+	}
+	if !matched {
+		match.middlewareOverrides = overrides
+		return false
+	}
+	// Build middleware chain if no error was found
+	if match.MatchErr == nil {
+		match.addMiddlewareOverrides(route.middlewareOverrides)
+		mws := match.routerMiddlewares(r)
+		for i := len(mws) - 1; i >= 0; i-- {
+			match.Handler = mws[i].Middleware(match.Handler)
+		}
+		match.addMiddlewareOverrides(r.middlewareOverrides)
+	} else {
+		// The handler of a subrouter for unmatched requests.
+		r.unmatchedChain(match, nil)
+	}
+	return true
+}
+
+// matchAutoMethod answers HEAD and OPTIONS requests that only failed to match
+// routes on their method, see AutoHead and AutoOptions, if enabled on a router
+// of the unmatched chain. Otherwise, it adds the methods it would answer to
+// the allowed methods.
+func (r *Router) matchAutoMethod(req *http.Request, match *RouteMatch) bool {
+	var autoHead, autoOptions bool
+	for _, u := range match.unmatched {
+		autoHead = autoHead || u.router.autoHead
+		autoOptions = autoOptions || u.router.autoOptions
+	}
+	if autoHead && matchInArray(match.AllowedMethods, http.MethodGet) {
+		if req.Method == http.MethodHead {
+			get := req.WithContext(req.Context())
+			get.Method = http.MethodGet
+			var getMatch RouteMatch
+			if r.Match(get, &getMatch) && getMatch.MatchErr == nil && getMatch.Handler != nil {
+				depth := match.depth
+				*match = getMatch
+				match.depth = depth
+				match.Handler = headHandler(getMatch.Handler)
+				return true
+			}
+		}
+		if !matchInArray(match.AllowedMethods, http.MethodHead) {
+			match.AllowedMethods = append(match.AllowedMethods, http.MethodHead)
+		}
+	}
+	if autoOptions {
+		if !matchInArray(match.AllowedMethods, http.MethodOptions) {
+			match.AllowedMethods = append(match.AllowedMethods, http.MethodOptions)
+		}
+		if req.Method == http.MethodOptions {
+			allow := strings.Join(match.AllowedMethods, ", ")
+			match.MatchErr = nil
+			match.AllowedMethods = nil
+			match.Handler = match.unmatchedHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Allow", allow)
+				w.WriteHeader(http.StatusNoContent)
+			}), true)
+			return true
+		}
+	}
 	return false
 }
 
+// headHandler serves HEAD requests with a GET handler, discarding the body.
+func headHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(headResponseWriter{w}, req)
+	})
+}
+
+// headResponseWriter discards the body of a response.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Flush implements http.Flusher if the wrapped writer does.
+func (w headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // ServeHTTP dispatches the handler registered in the matched route.
 //
 // When there is a match, the route variables can be retrieved calling
 // mux.Vars(request).
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, hook := range r.onRequest {
+		if hr := hook(req); hr != nil {
+			req = hr
+		}
+	}
 	if !r.skipClean {
 		path := req.URL.Path
 		if r.useEncodedPath {
@@ -199,44 +422,65 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 	var match RouteMatch
-	var handler http.Handler
-	if r.Match(req, &match) {
-		handler = match.Handler
-		if handler != nil {
-			// Populate context for custom handlers
-			if r.omitRouteFromContext {
-				// Only populate the match vars (if any) into the context.
-				req = requestWithVars(req, match.Vars)
-			} else {
-				req = requestWithRouteAndVars(req, match.Route, match.Vars)
-			}
+	if !r.Match(req, &match) {
+		match.Handler = nil
+	}
+	for _, hook := range r.onMatch {
+		hook(req, &match)
+	}
+	handler := match.Handler
+	if handler != nil && match.MatchErr == nil && match.Route != nil {
+		handler = match.Route.limitHandler(handler)
+	}
+	if handler != nil {
+		// Populate context for custom handlers
+		if r.omitRouteFromContext {
+			// Only populate the match vars (if any) into the context.
+			req = requestWithVars(req, match.Vars)
+		} else {
+			req = requestWithRouteAndVars(req, match.Route, match.Vars)
+		}
 
-			if !r.omitRouterFromContext {
-				req = requestWithRouter(req, r)
-			}
+		if !r.omitRouterFromContext {
+			req = requestWithRouter(req, r)
+		}
+
+		if r.populatePathValues && match.Route != nil {
+			req = requestWithPathValues(req, match.Route, match.Vars)
 		}
 	}
 
-	if handler == nil && match.MatchErr == ErrMethodMismatch {
-		handler = methodNotAllowedHandler()
+	if match.MatchErr == ErrMethodMismatch {
+		if len(match.AllowedMethods) > 0 {
+			req = req.WithContext(context.WithValue(req.Context(), allowedMethodsKey, match.AllowedMethods))
+		}
+		if handler == nil {
+			handler = methodNotAllowedHandler()
+		}
 	}
 
 	if handler == nil {
 		handler = http.NotFoundHandler()
 	}
 
+	if match.MatchErr != nil {
+		handler = match.unmatchedHandler(handler, false)
+	}
+
 	handler.ServeHTTP(w, req)
 }
 
 // Get returns a route registered with the given name.
 func (r *Router) Get(name string) *Route {
+	namedRoutesMu.RLock()
+	defer namedRoutesMu.RUnlock()
 	return r.namedRoutes[name]
 }
 
 // GetRoute returns a route registered with the given name. This method
 // was renamed to Get() and remains here for backwards compatibility.
 func (r *Router) GetRoute(name string) *Route {
-	return r.namedRoutes[name]
+	return r.Get(name)
 }
 
 // StrictSlash defines the trailing slash behavior for new routes. The initial
@@ -276,6 +520,21 @@ func (r *Router) SkipClean(value bool) *Router {
 	return r
 }
 
+// PopulatePathValues defines whether the router also exposes the matched
+// route through the http.Request fields and methods added in Go 1.22 and
+// 1.23, for handlers and middlewares written for http.ServeMux: every route
+// variable is set with Request.SetPathValue, and Request.Pattern is set to
+// the host and path templates of the route, including those of its parent
+// routes. The initial value is false. Subrouters created afterwards inherit
+// the setting.
+//
+// When built with Go 1.22, only the path values are set. With older
+// versions, the setting has no effect.
+func (r *Router) PopulatePathValues(value bool) *Router {
+	r.populatePathValues = value
+	return r
+}
+
 // OmitRouteFromContext defines the behavior of omitting the Route from the
 //
 //	http.Request context.
@@ -295,6 +554,107 @@ func (r *Router) OmitRouterFromContext(value bool) *Router {
 	return r
 }
 
+// RadixMatching defines whether the router looks up routes in a radix tree
+// of their path templates instead of trying every route in turn. The initial
+// value is false. Subrouters created afterwards inherit the setting.
+//
+// Only the routes whose path may match the request are tried, still in the
+// order they were added, so the result of a match is the same as without the
+// tree: the first matching route wins and method mismatches are reported as
+// usual. Literal path segments are looked up directly, segments with variables
+// are left to the route regexps.
+//
+// This is useful for routers with many routes. The tree is built on the first
+// match and rebuilt when routes are added, so routes should be fully
+// configured before the router starts serving requests.
+func (r *Router) RadixMatching(value bool) *Router {
+	r.radixMatching = value
+	if value && r.index == nil {
+		r.index = &routeIndexCache{}
+	}
+	return r
+}
+
+// RegisterPattern registers a named pattern that templates of the routes
+// registered afterwards can use as {name:@pattern}, to share a regexp between
+// routes. Subrouters created afterwards inherit the patterns.
+// For example:
+//
+//	r := mux.NewRouter().RegisterPattern("sha", "[0-9a-f]{40}")
+//	r.HandleFunc("/commits/{commit:@sha}", CommitHandler)
+//
+// The name must be an identifier. URL building validates variable values
+// against the same pattern. A pattern starting with @ that isn't a registered
+// name, such as {handle:@[a-z]+}, is used as a regexp like any other.
+func (r *Router) RegisterPattern(name, pattern string) *Router {
+	patterns := make(map[string]string, len(r.patterns)+1)
+	for k, v := range r.patterns {
+		patterns[k] = v
+	}
+	patterns[name] = pattern
+	r.patterns = patterns
+	return r
+}
+
+// OptionalParts defines whether the templates of new routes can have optional
+// parts and default values. The initial value is false. Subrouters created
+// afterwards inherit the setting.
+//
+// When true, parts of a path template enclosed in square brackets are
+// optional, and variables can have a default value, used when they are not
+// matched or not given to build a URL:
+//
+//	r := mux.NewRouter().OptionalParts(true)
+//	r.HandleFunc("/reports[/{year:[0-9]{4}}][.{format=json}]", ReportsHandler)
+//
+// When false, brackets are matched literally and "=" is part of the variable
+// name, as in older versions.
+func (r *Router) OptionalParts(value bool) *Router {
+	r.optionalParts = value
+	return r
+}
+
+// AutoOptions defines whether the router answers OPTIONS requests for paths
+// that routes match with other methods. The initial value is false.
+// Subrouters created afterwards inherit the setting.
+//
+// When true, such requests get a 204 No Content response with an Allow
+// header listing the methods of the routes matching the request, wrapped in
+// the middlewares of the routers matching it, with Without and Replace
+// applied. Routes registered with the OPTIONS method are matched as usual
+// and take precedence, including the routes added to the routers above a
+// subrouter after it.
+func (r *Router) AutoOptions(value bool) *Router {
+	r.autoOptions = value
+	return r
+}
+
+// AutoHead defines whether HEAD requests are served by the routes matching
+// the same request with the GET method, with the response body discarded.
+// The initial value is false. Subrouters created afterwards inherit the
+// setting. Routes registered with the HEAD method are matched as usual and
+// take precedence, including the routes added to the routers above a
+// subrouter after it.
+func (r *Router) AutoHead(value bool) *Router {
+	r.autoHead = value
+	return r
+}
+
+// UseForUnmatched defines whether the router middlewares also wrap the
+// handlers of requests that match no route: the NotFoundHandler and
+// MethodNotAllowedHandler, or their defaults. The initial value is false.
+// Subrouters created afterwards inherit the setting.
+//
+// When true, ServeHTTP wraps such handlers in the middlewares of the deepest
+// subrouter whose route partially matched the request, and of the routers
+// above it, in the same order as the handler of a matching route, with
+// Without and Replace applied. Routers with the setting disabled are left
+// out.
+func (r *Router) UseForUnmatched(value bool) *Router {
+	r.useForUnmatched = value
+	return r
+}
+
 // UseEncodedPath tells the router to match the encoded original path
 // to the routes.
 // For eg. "/path/foo%2Fbar/to" will match the path "/path/{var}/to".
@@ -306,6 +666,82 @@ func (r *Router) UseEncodedPath() *Router {
 	return r
 }
 
+// Freeze validates the routes of the router and all its subrouters, builds
+// their matching indexes and returns the router as an http.Handler that can
+// no longer be modified.
+//
+// If any route has an error, see Route.GetError, the router is left as is and
+// all the errors are returned. Otherwise the router and its subrouters are
+// frozen and switched to radix matching, see Router.RadixMatching. Routes
+// registered on a frozen router are never matched and report ErrRouterFrozen
+// from Route.GetError, and adding middleware to a frozen router panics.
+// Matchers added to the routes of a frozen router are ignored, and their
+// subrouters are frozen and never matched.
+//
+// Freeze is meant to be called once all routes are registered and before the
+// router starts serving requests:
+//
+//	r := mux.NewRouter()
+//	r.HandleFunc("/products/{key}", ProductHandler)
+//	h, err := r.Freeze()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.ListenAndServe(":8080", h)
+func (r *Router) Freeze() (http.Handler, error) {
+	if r.conflicts != nil {
+		r.conflicts.check()
+	}
+	var errs []error
+	err := r.Walk(func(route *Route, _ *Router, _ []*Route) error {
+		if err := route.GetError(); err != nil {
+			errs = append(errs, fmt.Errorf("mux: invalid route %s: %w", route.describe(), err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	r.freeze()
+	return frozenRouter{r}, nil
+}
+
+// freeze marks the router and its subrouters as frozen and builds their
+// indexes.
+func (r *Router) freeze() {
+	r.frozen = true
+	r.radixMatching = true
+	if r.index == nil {
+		r.index = &routeIndexCache{}
+	}
+	routes, gen := r.routeList()
+	r.index.get(routes, gen)
+	for _, route := range routes {
+		route.frozen = true
+		for _, sr := range route.subrouters() {
+			sr.freeze()
+		}
+	}
+}
+
+// IsFrozen reports whether Router.Freeze was called on the router or on one
+// of its parents.
+func (r *Router) IsFrozen() bool {
+	return r.frozen
+}
+
+// frozenRouter is the handler returned by Router.Freeze.
+type frozenRouter struct {
+	router *Router
+}
+
+func (f frozenRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.router.ServeHTTP(w, req)
+}
+
 // ----------------------------------------------------------------------------
 // Route factories
 // ----------------------------------------------------------------------------
@@ -314,10 +750,97 @@ func (r *Router) UseEncodedPath() *Router {
 func (r *Router) NewRoute() *Route {
 	// initialize a route with a copy of the parent router's configuration
 	route := &Route{routeConf: copyRouteConf(r.routeConf), namedRoutes: r.namedRoutes}
+	if r.frozen {
+		route.err = ErrRouterFrozen
+		return route
+	}
+	r.mu.Lock()
 	r.routes = append(r.routes, route)
+	r.gen++
+	r.mu.Unlock()
+	if r.conflicts != nil {
+		r.conflicts.gen.Add(1)
+	}
 	return route
 }
 
+// routeList returns the routes of the router and their generation, which
+// changes whenever routes are added or removed. The slice is never modified
+// once returned, so it can be used while routes are added or removed.
+func (r *Router) routeList() ([]*Route, uint64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.routes[:len(r.routes):len(r.routes)], r.gen
+}
+
+// Remove unregisters a route from the router or from one of its subrouters.
+// It reports whether the route was found.
+//
+// The route and the routes of its subrouters are also removed from the named
+// routes used to build URLs. Remove is safe to call while the router serves
+// requests: requests being matched when the route is removed may still be
+// dispatched to it. Routes can't be removed from a frozen router, see
+// Router.Freeze.
+func (r *Router) Remove(route *Route) bool {
+	if route == nil || r.frozen {
+		return false
+	}
+	routes, _ := r.routeList()
+	for _, t := range routes {
+		if t == route {
+			r.removeRoute(route)
+			return true
+		}
+	}
+	for _, t := range routes {
+		for _, sr := range t.subrouters() {
+			if sr.Remove(route) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveByName unregisters the route with the given name, see Router.Remove.
+// It reports whether the route was found.
+func (r *Router) RemoveByName(name string) bool {
+	return r.Remove(r.Get(name))
+}
+
+// removeRoute removes a route of the router and forgets the names of the
+// route and of the routes of its subrouters.
+func (r *Router) removeRoute(route *Route) {
+	r.mu.Lock()
+	routes := make([]*Route, 0, len(r.routes))
+	for _, t := range r.routes {
+		if t != route {
+			routes = append(routes, t)
+		}
+	}
+	r.routes = routes
+	r.gen++
+	r.mu.Unlock()
+	if r.conflicts != nil {
+		r.conflicts.gen.Add(1)
+	}
+
+	namedRoutesMu.Lock()
+	defer namedRoutesMu.Unlock()
+	forget := func(t *Route) {
+		if t.name != "" && t.namedRoutes[t.name] == t {
+			delete(t.namedRoutes, t.name)
+		}
+	}
+	forget(route)
+	for _, sr := range route.subrouters() {
+		_ = sr.Walk(func(t *Route, _ *Router, _ []*Route) error {
+			forget(t)
+			return nil
+		})
+	}
+}
+
 // Name registers a new route with a name.
 // See Route.Name().
 func (r *Router) Name(name string) *Route {
@@ -408,7 +931,8 @@ var SkipRouter = errors.New("skip this router")
 type WalkFunc func(route *Route, router *Router, ancestors []*Route) error
 
 func (r *Router) walk(walkFn WalkFunc, ancestors []*Route) error {
-	for _, t := range r.routes {
+	routes, _ := r.routeList()
+	for _, t := range routes {
 		err := walkFn(t, r, ancestors)
 		if err == SkipRouter {
 			continue
@@ -452,6 +976,30 @@ type RouteMatch struct {
 	// It is set to ErrMethodMismatch if there is a mismatch in
 	// the request method and route method
 	MatchErr error
+
+	// AllowedMethods lists, when MatchErr is ErrMethodMismatch, the methods
+	// of the routes that matched the request except for their method, in
+	// the order the routes were added. See also AllowedMethods.
+	AllowedMethods []string
+
+	// The routers that partially matched the request, outermost first, when
+	// MatchErr is not nil. See Router.UseForUnmatched.
+	unmatched []unmatchedRouter
+
+	// The number of routers matching the request, the top-level router
+	// included, see Router.Match.
+	depth int
+
+	// The middleware overrides of the matched route and the routers and
+	// routes above it, see Router.Without and Router.Replace.
+	middlewareOverrides map[string]middleware
+}
+
+// unmatchedRouter is a router partially matching a request, with the route
+// of its parent router it is the subrouter of, if any.
+type unmatchedRouter struct {
+	router *Router
+	route  *Route
 }
 
 type contextKey int
@@ -460,6 +1008,7 @@ const (
 	varsKey contextKey = iota
 	routeKey
 	routerKey
+	allowedMethodsKey
 )
 
 // Vars returns the route variables for the current request, if any.
@@ -488,6 +1037,16 @@ func CurrentRouter(r *http.Request) *Router {
 	return nil
 }
 
+// AllowedMethods returns the methods allowed for the current request when it
+// only failed to match routes on their method, for use in a
+// Router.MethodNotAllowedHandler. See RouteMatch.AllowedMethods.
+func AllowedMethods(r *http.Request) []string {
+	if rv := r.Context().Value(allowedMethodsKey); rv != nil {
+		return rv.([]string)
+	}
+	return nil
+}
+
 // requestWithVars adds the matched vars to the request ctx.
 // It shortcuts the operation when the vars are empty.
 func requestWithVars(r *http.Request, vars map[string]string) *http.Request {
@@ -668,9 +1227,13 @@ func matchMapWithRegex(toCheck map[string]*regexp.Regexp, toMatch map[string][]s
 
 // methodNotAllowed replies to the request with an HTTP status code 405.
 func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	if methods := AllowedMethods(r); len(methods) > 0 {
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+	}
 	w.WriteHeader(http.StatusMethodNotAllowed)
 }
 
 // methodNotAllowedHandler returns a simple request handler
-// that replies to each request with a status code 405.
+// that replies to each request with a status code 405 and an Allow header
+// listing the allowed methods.
 func methodNotAllowedHandler() http.Handler { return http.HandlerFunc(methodNotAllowed) }
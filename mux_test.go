@@ -16,7 +16,9 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -2943,6 +2945,238 @@ func TestSubrouterCustomMethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestMethodNotAllowedAllowHeader(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := NewRouter()
+	router.HandleFunc("/thing", handler).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/thing", handler).Methods(http.MethodPost)
+	router.HandleFunc("/thing", handler).Methods(http.MethodDelete).Queries("force", "1")
+	router.HandleFunc("/other", handler).Methods(http.MethodPatch)
+	subrouter := router.PathPrefix("/thing").Subrouter()
+	subrouter.HandleFunc("", handler).Methods(http.MethodGet, http.MethodPut)
+
+	var match RouteMatch
+	if router.Match(newRequest(http.MethodOptions, "/thing"), &match) || match.MatchErr != ErrMethodMismatch {
+		t.Fatalf("Expected a method mismatch, got %v", match.MatchErr)
+	}
+	want := []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut}
+	if !reflect.DeepEqual(match.AllowedMethods, want) {
+		t.Errorf("Expected allowed methods %v, got %v", want, match.AllowedMethods)
+	}
+
+	w := NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodOptions, "/thing"))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status code 405 (got %d)", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, POST, PUT" {
+		t.Errorf("Expected Allow header %q, got %q", "GET, HEAD, POST, PUT", allow)
+	}
+
+	// A later matching route clears the allowed methods.
+	router.HandleFunc("/thing", handler)
+	match = RouteMatch{}
+	if !router.Match(newRequest(http.MethodOptions, "/thing"), &match) || match.AllowedMethods != nil {
+		t.Errorf("Expected a match without allowed methods, got %v", match.AllowedMethods)
+	}
+}
+
+func TestCustomMethodNotAllowedAllowedMethods(t *testing.T) {
+	router := NewRouter()
+	router.HandleFunc("/thing", dummyHandler).Methods(http.MethodGet)
+	var allowed []string
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed = AllowedMethods(r)
+	})
+
+	router.ServeHTTP(NewRecorder(), newRequest(http.MethodPost, "/thing"))
+	if !reflect.DeepEqual(allowed, []string{http.MethodGet}) {
+		t.Errorf("Expected the handler to get the allowed methods, got %v", allowed)
+	}
+}
+
+func TestAutoOptions(t *testing.T) {
+	router := NewRouter().AutoOptions(true)
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Middleware", "1")
+			next.ServeHTTP(w, req)
+		})
+	})
+	router.HandleFunc("/thing", dummyHandler).Methods(http.MethodGet)
+	router.HandleFunc("/thing", dummyHandler).Methods(http.MethodPost)
+	router.HandleFunc("/explicit", dummyHandler).Methods(http.MethodGet)
+	router.HandleFunc("/explicit", stringHandler("options")).Methods(http.MethodOptions)
+	subrouter := router.PathPrefix("/api").Subrouter()
+	subrouter.HandleFunc("/items", dummyHandler).Methods(http.MethodPut)
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/thing", "GET, POST, OPTIONS"},
+		{"/api/items", "PUT, OPTIONS"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newRequest(http.MethodOptions, tt.path))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("%s: expected status code 204, got %d", tt.path, w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s: expected Allow header %q, got %q", tt.path, tt.allow, allow)
+		}
+		if w.Header().Get("X-Middleware") != "1" {
+			t.Errorf("%s: expected the response to go through the middlewares", tt.path)
+		}
+	}
+
+	// An explicit OPTIONS route takes precedence.
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodOptions, "/explicit"))
+	if w.Body.String() != "options" {
+		t.Errorf("Expected the OPTIONS route to serve the request, got %q", w.Body.String())
+	}
+
+	// Other methods still get a 405, listing OPTIONS as allowed.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodDelete, "/thing"))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code 405, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, POST, OPTIONS" {
+		t.Errorf("Expected Allow header %q, got %q", "GET, POST, OPTIONS", allow)
+	}
+
+	// Unknown paths are not found.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodOptions, "/unknown"))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestAutoOptionsSubrouter(t *testing.T) {
+	writer := func(s string) MiddlewareFunc {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", s)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter().AutoOptions(true)
+	router.UseNamed("auth", writer("auth"))
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/x", dummyHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/x", dummyHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/x", stringHandler("options")).Methods(http.MethodOptions)
+	router.HandleFunc("/api/y", dummyHandler).Methods(http.MethodPost)
+	public := router.PathPrefix("/public").Subrouter().Without("auth")
+	public.Use(writer("public"))
+	public.HandleFunc("/z", dummyHandler).Methods(http.MethodGet)
+
+	// Routes of the parent router added after the subrouter still match.
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodOptions, "/api/x"))
+	if w.Body.String() != "options" {
+		t.Errorf("Expected the OPTIONS route to serve the request, got %d %q", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		path  string
+		allow string
+		mws   []string
+	}{
+		{"/api/y", "POST, OPTIONS", []string{"auth"}},
+		{"/public/z", "GET, OPTIONS", []string{"public"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newRequest(http.MethodOptions, tt.path))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("%s: expected status code 204, got %d", tt.path, w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s: expected Allow header %q, got %q", tt.path, tt.allow, allow)
+		}
+		if mws := w.Header().Values("X-Middleware"); !reflect.DeepEqual(mws, tt.mws) {
+			t.Errorf("%s: expected the middlewares %v, got %v", tt.path, tt.mws, mws)
+		}
+	}
+}
+
+func TestAutoHead(t *testing.T) {
+	var method string
+	router := NewRouter().AutoHead(true).AutoOptions(true)
+	router.HandleFunc("/thing", func(w http.ResponseWriter, req *http.Request) {
+		method = req.Method
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("body"))
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/explicit", stringHandler("get")).Methods(http.MethodGet)
+	router.HandleFunc("/explicit", stringHandler("head")).Methods(http.MethodHead)
+	router.HandleFunc("/post", dummyHandler).Methods(http.MethodPost)
+	subrouter := router.PathPrefix("/api").Subrouter()
+	subrouter.HandleFunc("/items/{id}", stringHandler("item")).Methods(http.MethodGet)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodHead, "/thing"))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("Expected status code 200 and no body, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("Expected the GET handler headers, got %v", w.Header())
+	}
+	if method != http.MethodHead {
+		t.Errorf("Expected the handler to see a HEAD request, got %s", method)
+	}
+
+	req := newRequest(http.MethodHead, "/api/items/42")
+	var match RouteMatch
+	if !router.Match(req, &match) || match.Vars["id"] != "42" {
+		t.Errorf("Expected a match with variables, got %v %v", match.MatchErr, match.Vars)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("Expected status code 200 and no body, got %d %q", w.Code, w.Body.String())
+	}
+
+	// An explicit HEAD route takes precedence.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodHead, "/explicit"))
+	if w.Body.String() != "head" {
+		t.Errorf("Expected the HEAD route to serve the request, got %q", w.Body.String())
+	}
+
+	// Paths without GET routes are not served.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodHead, "/post"))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodOptions, "/thing"))
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("Expected Allow header %q, got %q", "GET, HEAD, OPTIONS", allow)
+	}
+
+	// The response writer can be flushed and unwrapped.
+	var flushErr error
+	router.HandleFunc("/stream", func(w http.ResponseWriter, req *http.Request) {
+		flushErr = http.NewResponseController(w).Flush()
+	}).Methods(http.MethodGet)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodHead, "/stream"))
+	if flushErr != nil || !w.Flushed {
+		t.Errorf("Expected the response to be flushed, got %v", flushErr)
+	}
+}
+
 func TestSubrouterNotFound(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
 	router := NewRouter()
@@ -3239,3 +3473,218 @@ func newRequestHost(method, url, host string) *http.Request {
 	req.Host = host
 	return req
 }
+
+func TestFreeze(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("/a", stringHandler("a")).Name("a")
+	s := r.PathPrefix("/sub").Name("sub").Subrouter()
+	s.HandleFunc("/b", stringHandler("b"))
+
+	h, err := r.Freeze()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !r.IsFrozen() || !s.IsFrozen() {
+		t.Fatal("Expected the router and its subrouter to be frozen")
+	}
+	if s.index.idx.Load() == nil {
+		t.Error("Expected the subrouter index to be built")
+	}
+
+	for path, body := range map[string]string{"/a": "a", "/sub/b": "b"} {
+		rec := NewRecorder()
+		h.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost"+path))
+		if rec.Body.String() != body {
+			t.Errorf("Expected body %q for %s, got %q", body, path, rec.Body.String())
+		}
+	}
+
+	late := r.HandleFunc("/late", stringHandler("late"))
+	if late.GetError() != ErrRouterFrozen {
+		t.Errorf("Expected ErrRouterFrozen, got %v", late.GetError())
+	}
+	if late = s.HandleFunc("/late", stringHandler("late")); late.GetError() != ErrRouterFrozen {
+		t.Errorf("Expected ErrRouterFrozen from the subrouter, got %v", late.GetError())
+	}
+	if len(r.routes) != 2 || len(s.routes) != 1 {
+		t.Error("Expected no routes to be added after freezing")
+	}
+	rec := NewRecorder()
+	h.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost/late"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	// Existing routes can't get new matchers or subrouters.
+	a := r.Get("a")
+	a.Methods(http.MethodPost).Path("/other")
+	if a.GetError() != nil {
+		t.Errorf("Unexpected error %v", a.GetError())
+	}
+	late2 := a.Subrouter()
+	if !late2.IsFrozen() {
+		t.Error("Expected the subrouter of a frozen route to be frozen")
+	}
+	if route := late2.HandleFunc("/late", stringHandler("late")); route.GetError() != ErrRouterFrozen {
+		t.Errorf("Expected ErrRouterFrozen from the late subrouter, got %v", route.GetError())
+	}
+	sub := r.Get("sub")
+	sub.Subrouter().HandleFunc("/late", stringHandler("late"))
+	for path, code := range map[string]int{"/a": http.StatusOK, "/sub/late": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost"+path))
+		if rec.Code != code {
+			t.Errorf("Expected status %d for %s, got %d", code, path, rec.Code)
+		}
+	}
+
+	mw := func(h http.Handler) http.Handler { return h }
+	for name, f := range map[string]func(){
+		"Router.Use":      func() { r.Use(mw) },
+		"Router.UseNamed": func() { s.UseNamed("log", mw) },
+		"Router.Without":  func() { s.Without("log") },
+		"Router.Replace":  func() { s.Replace("log", mw) },
+		"Route.Use":       func() { a.Use(mw) },
+		"Route.UseNamed":  func() { a.UseNamed("log", mw) },
+		"Route.Without":   func() { a.Without("log") },
+		"Route.Replace":   func() { sub.Replace("log", mw) },
+	} {
+		func() {
+			defer func() {
+				if recover() != ErrRouterFrozen {
+					t.Errorf("Expected %s to panic with ErrRouterFrozen", name)
+				}
+			}()
+			f()
+		}()
+	}
+	if len(a.middlewares) != 0 || len(s.middlewareOverrides) != 0 || len(sub.middlewareOverrides) != 0 {
+		t.Error("Expected the middlewares not to change after freezing")
+	}
+}
+
+func TestFreezeInvalidRoutes(t *testing.T) {
+	r := NewRouter()
+	r.Host("{a}.example.com").Path("/{a}").HandlerFunc(stringHandler("a"))
+	r.HandleFunc("/b", stringHandler("b")).Name("b").Name("c")
+
+	h, err := r.Freeze()
+	if err == nil || h != nil {
+		t.Fatal("Expected an error for invalid routes")
+	}
+	for _, want := range []string{`"{a}.example.com"`, `"b"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error %q to mention route %s", err, want)
+		}
+	}
+	if r.IsFrozen() {
+		t.Error("Expected the router not to be frozen")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	r := NewRouter()
+	a := r.HandleFunc("/a", stringHandler("a")).Name("a")
+	r.HandleFunc("/a", stringHandler("a2"))
+	api := r.PathPrefix("/api")
+	s := api.Subrouter()
+	s.HandleFunc("/b", stringHandler("b")).Name("b")
+	nested := s.HandleFunc("/c", stringHandler("c")).Name("c")
+
+	serve := func(path string) string {
+		rec := NewRecorder()
+		r.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost"+path))
+		return rec.Body.String()
+	}
+
+	if !r.Remove(a) {
+		t.Fatal("Expected the route to be removed")
+	}
+	if r.Remove(a) {
+		t.Error("Expected a removed route not to be found again")
+	}
+	if r.Get("a") != nil {
+		t.Error("Expected the name of the removed route to be forgotten")
+	}
+	if body := serve("/a"); body != "a2" {
+		t.Errorf("Expected the next route to match, got %q", body)
+	}
+
+	if !r.Remove(nested) {
+		t.Fatal("Expected the subrouter route to be removed")
+	}
+	if r.Get("c") != nil || serve("/api/c") != "404 page not found\n" {
+		t.Error("Expected the subrouter route to be gone")
+	}
+
+	if !r.Remove(api) {
+		t.Fatal("Expected the subrouter route to be removed")
+	}
+	if r.Get("b") != nil {
+		t.Error("Expected the names of the subrouter routes to be forgotten")
+	}
+	if body := serve("/api/b"); body != "404 page not found\n" {
+		t.Errorf("Expected no match, got %q", body)
+	}
+}
+
+func TestRemoveByName(t *testing.T) {
+	r := NewRouter().RadixMatching(true)
+	r.HandleFunc("/a", stringHandler("a")).Name("a")
+
+	var match RouteMatch
+	if !r.Match(newRequest(http.MethodGet, "http://localhost/a"), &match) {
+		t.Fatal("Expected a match")
+	}
+	if !r.RemoveByName("a") {
+		t.Fatal("Expected the route to be removed")
+	}
+	if r.RemoveByName("a") || r.RemoveByName("unknown") {
+		t.Error("Expected unknown names not to be found")
+	}
+	match = RouteMatch{}
+	if r.Match(newRequest(http.MethodGet, "http://localhost/a"), &match) {
+		t.Error("Expected no match after removal")
+	}
+
+	r.HandleFunc("/b", stringHandler("b"))
+	match = RouteMatch{}
+	if !r.Match(newRequest(http.MethodGet, "http://localhost/b"), &match) {
+		t.Error("Expected the index to be rebuilt after adding a route")
+	}
+}
+
+func TestRemoveFrozen(t *testing.T) {
+	r := NewRouter()
+	a := r.HandleFunc("/a", stringHandler("a"))
+	if _, err := r.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Remove(a) {
+		t.Error("Expected routes of a frozen router not to be removed")
+	}
+}
+
+func TestRemoveWhileServing(t *testing.T) {
+	r := NewRouter().RadixMatching(true)
+	for i := 0; i < 10; i++ {
+		r.HandleFunc(fmt.Sprintf("/%d", i), stringHandler("x")).Name(strconv.Itoa(i))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			r.ServeHTTP(NewRecorder(), newRequest(http.MethodGet, fmt.Sprintf("http://localhost/%d", i)))
+			r.Get(strconv.Itoa(i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			r.RemoveByName(strconv.Itoa(i))
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,383 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openapi generates OpenAPI 3.1 documents from the routes of a
+// mux.Router.
+//
+// Every route with a handler and a path becomes one operation per method.
+// Path templates are converted to OpenAPI paths, route variables to path and
+// query parameters constrained by their patterns, and host templates and
+// schemes to servers. Operations are described with route metadata:
+//
+//	r := mux.NewRouter()
+//	r.HandleFunc("/users/{id:int}", GetUser).
+//		Methods(http.MethodGet).
+//		Name("getUser").
+//		Metadata(openapi.Summary, "Get a user").
+//		Metadata(openapi.Tags, []string{"users"})
+//
+//	doc, err := openapi.Generate(r, openapi.Options{Title: "Users", Version: "1.0"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	b, _ := doc.JSON()
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Version is the OpenAPI version of the generated documents.
+const Version = "3.1.0"
+
+// MetadataKey is the type of the route metadata keys read by Generate.
+type MetadataKey string
+
+const (
+	// Summary is the metadata key for the summary of an operation, a string.
+	Summary MetadataKey = "openapi.summary"
+	// Description is the metadata key for the description of an operation,
+	// a string.
+	Description MetadataKey = "openapi.description"
+	// Tags is the metadata key for the tags of an operation, a []string.
+	Tags MetadataKey = "openapi.tags"
+	// OperationID is the metadata key for the id of an operation, a string.
+	// The name of the route is used by default.
+	OperationID MetadataKey = "openapi.operationId"
+	// Deprecated is the metadata key to mark an operation as deprecated, a
+	// bool.
+	Deprecated MetadataKey = "openapi.deprecated"
+	// Hidden is the metadata key to leave a route out of the document, a
+	// bool.
+	Hidden MetadataKey = "openapi.hidden"
+)
+
+// Options configures the generated document.
+type Options struct {
+	Title       string
+	Version     string
+	Description string
+	// Servers of the document. The operations of routes with a host template
+	// get their own servers.
+	Servers []Server
+}
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI string               `json:"openapi"`
+	Info    Info                 `json:"info"`
+	Servers []Server             `json:"servers,omitempty"`
+	Paths   map[string]*PathItem `json:"paths"`
+}
+
+// Info is the metadata of a Document.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a server of a Document or an Operation.
+type Server struct {
+	URL         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable is a variable in the URL of a Server.
+type ServerVariable struct {
+	Default string `json:"default"`
+	Pattern string `json:"x-pattern,omitempty"`
+}
+
+// PathItem holds the operations of a path.
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+	Trace   *Operation `json:"trace,omitempty"`
+}
+
+// Operation describes an operation on a path.
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Servers     []Server            `json:"servers,omitempty"`
+}
+
+// Parameter is a path or query parameter of an Operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Schema is the schema of a Parameter.
+type Schema struct {
+	Type    string   `json:"type"`
+	Format  string   `json:"format,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+	Minimum *int     `json:"minimum,omitempty"`
+	Default any      `json:"default,omitempty"`
+}
+
+// Response is a response of an Operation.
+type Response struct {
+	Description string `json:"description"`
+}
+
+// Generate walks the router and its subrouters and returns a document
+// describing their routes.
+//
+// Routes without a handler or a path, such as the routes of subrouters, are
+// skipped, as are build-only routes and routes with Hidden metadata. A route
+// without methods is documented as a GET operation. When several routes have
+// the same path and method, the first one registered is documented, as it is
+// the one matching requests. Optional parts of a path, as in
+// /reports[/{year}], give one path per combination, see
+// mux.Route.GetPathVariants. Path prefixes are documented as plain paths.
+// Routes with a host template get servers for their operations; host
+// variables without a default value get their name as default.
+//
+// Generate returns an error if a route has an error, see Route.GetError.
+func Generate(r *mux.Router, opts Options) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       opts.Title,
+			Version:     opts.Version,
+			Description: opts.Description,
+		},
+		Servers: opts.Servers,
+		Paths:   make(map[string]*PathItem),
+	}
+	var errs []error
+	_ = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if err := route.GetError(); err != nil {
+			if name := route.GetName(); name != "" {
+				err = fmt.Errorf("route %q: %w", name, err)
+			}
+			errs = append(errs, fmt.Errorf("openapi: %w", err))
+			return nil
+		}
+		if route.GetHandler() == nil || route.IsBuildOnly() || route.GetMetadataValueOr(Hidden, false) == true {
+			return nil
+		}
+		paths, err := route.GetPathVariants()
+		if err != nil {
+			return nil
+		}
+		doc.addRoute(route, paths)
+		return nil
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return doc, nil
+}
+
+// addRoute adds the operations of a route to the document, for each variant
+// of its path.
+func (d *Document) addRoute(route *mux.Route, paths []mux.TemplateVariant) {
+	vars, _ := route.GetVars()
+	methods, err := route.GetMethods()
+	if err != nil {
+		methods = []string{http.MethodGet}
+	}
+	servers := routeServers(route, vars)
+	query := queryParameters(route, vars)
+
+	for _, path := range paths {
+		item := d.Paths[path.Template]
+		if item == nil {
+			item = &PathItem{}
+			d.Paths[path.Template] = item
+		}
+		for _, method := range methods {
+			op := item.operation(method)
+			if op == nil || *op != nil {
+				continue
+			}
+			*op = newOperation(route, method, len(methods) > 1, pathParameters(path.Vars, vars), query)
+			(*op).Servers = servers
+		}
+	}
+}
+
+// operation returns the field of the path item for a method, or nil if the
+// method can't be described.
+func (p *PathItem) operation(method string) **Operation {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return &p.Get
+	case http.MethodPut:
+		return &p.Put
+	case http.MethodPost:
+		return &p.Post
+	case http.MethodDelete:
+		return &p.Delete
+	case http.MethodOptions:
+		return &p.Options
+	case http.MethodHead:
+		return &p.Head
+	case http.MethodPatch:
+		return &p.Patch
+	case http.MethodTrace:
+		return &p.Trace
+	}
+	return nil
+}
+
+// newOperation describes a route for a method.
+func newOperation(route *mux.Route, method string, multiple bool, params ...[]Parameter) *Operation {
+	op := &Operation{
+		Responses: map[string]Response{"default": {Description: "Default response"}},
+	}
+	op.OperationID, _ = route.GetMetadataValueOr(OperationID, route.GetName()).(string)
+	if op.OperationID != "" && multiple {
+		op.OperationID += "_" + strings.ToLower(method)
+	}
+	op.Summary, _ = route.GetMetadataValueOr(Summary, "").(string)
+	op.Description, _ = route.GetMetadataValueOr(Description, "").(string)
+	op.Tags, _ = route.GetMetadataValueOr(Tags, nil).([]string)
+	op.Deprecated, _ = route.GetMetadataValueOr(Deprecated, false).(bool)
+	for _, p := range params {
+		op.Parameters = append(op.Parameters, p...)
+	}
+	return op
+}
+
+// routeServers returns the servers of a route with a host template.
+func routeServers(route *mux.Route, vars []mux.RouteVar) []Server {
+	host, err := route.GetHostVariant()
+	if err != nil {
+		return nil
+	}
+	schemes, err := route.GetSchemes()
+	if err != nil {
+		schemes = []string{"http"}
+	}
+	hostVars := make(map[string]ServerVariable)
+	for _, v := range vars {
+		if v.Matcher != mux.MatcherHost {
+			continue
+		}
+		sv := ServerVariable{Default: v.Default, Pattern: anchoredPattern(v.Pattern, "[^.]+")}
+		if sv.Default == "" {
+			sv.Default = v.Name
+		}
+		hostVars[v.Name] = sv
+	}
+	if len(hostVars) == 0 {
+		hostVars = nil
+	}
+	servers := make([]Server, len(schemes))
+	for i, scheme := range schemes {
+		servers[i] = Server{URL: scheme + "://" + host.Template, Variables: hostVars}
+	}
+	return servers
+}
+
+// pathParameters returns the parameters for the variables of a path.
+func pathParameters(names []string, vars []mux.RouteVar) []Parameter {
+	var params []Parameter
+	for _, name := range names {
+		for _, v := range vars {
+			if v.Name == name && (v.Matcher == mux.MatcherPath || v.Matcher == mux.MatcherPathPrefix) {
+				params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: varSchema(v, "[^/]+")})
+				break
+			}
+		}
+	}
+	return params
+}
+
+// queryParameters returns the parameters for the query templates of a route.
+// Queries with a fixed value get an enum schema.
+func queryParameters(route *mux.Route, vars []mux.RouteVar) []Parameter {
+	queries, err := route.GetQueriesVariants()
+	if err != nil {
+		return nil
+	}
+	params := make([]Parameter, 0, len(queries))
+	for _, q := range queries {
+		key, value, _ := strings.Cut(q.Template, "=")
+		p := Parameter{Name: key, In: "query", Required: true, Schema: &Schema{Type: "string"}}
+		switch {
+		case len(q.Vars) == 0 && value != "":
+			p.Schema.Enum = []string{value}
+		case len(q.Vars) == 1 && value == "{"+q.Vars[0]+"}":
+			for _, v := range vars {
+				if v.Name == q.Vars[0] && v.Matcher == mux.MatcherQuery {
+					p.Schema = varSchema(v, ".*")
+					break
+				}
+			}
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// varSchema returns the schema for a route variable. The default pattern of
+// the variable is left out.
+func varSchema(v mux.RouteVar, defaultPattern string) *Schema {
+	s := &Schema{Type: "string"}
+	switch v.Type {
+	case "int":
+		s.Type = "integer"
+	case "uint":
+		s.Type = "integer"
+		s.Minimum = new(int)
+	case "uuid":
+		s.Format = "uuid"
+	case "date":
+		s.Format = "date"
+	default:
+		if !v.Wildcard {
+			s.Pattern = anchoredPattern(v.Pattern, defaultPattern)
+		}
+	}
+	if v.Default != "" {
+		s.Default = v.Default
+		if s.Type == "integer" {
+			if n, err := strconv.Atoi(v.Default); err == nil {
+				s.Default = n
+			}
+		}
+	}
+	return s
+}
+
+// anchoredPattern returns a variable pattern as an anchored regexp, or an
+// empty string for the default pattern.
+func anchoredPattern(pattern, defaultPattern string) string {
+	if pattern == defaultPattern {
+		return ""
+	}
+	return "^" + pattern + "$"
+}
+
+// JSON returns the document encoded as indented JSON.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
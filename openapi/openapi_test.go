@@ -0,0 +1,175 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func handler(http.ResponseWriter, *http.Request) {}
+
+func TestGenerate(t *testing.T) {
+	r := mux.NewRouter().OptionalParts(true)
+	r.HandleFunc("/users/{id:int}", handler).
+		Methods(http.MethodGet, http.MethodDelete).
+		Name("user").
+		Metadata(Summary, "A user").
+		Metadata(Tags, []string{"users"})
+	r.HandleFunc("/users/{id:[0-9]+}", handler).Methods(http.MethodGet).Metadata(Summary, "Shadowed")
+	r.HandleFunc("/search", handler).Queries("q", "{q}", "debug", "1", "limit", "{limit=10:uint}")
+	r.HandleFunc("/internal", handler).Metadata(Hidden, true)
+	r.HandleFunc("/reports[/{year:[0-9]{4}}]", handler).Methods(http.MethodGet).Metadata(Deprecated, true)
+	r.HandleFunc("/built", nil).BuildOnly()
+	api := r.Host("{tenant:[a-z]+}.example.com").Schemes("https").PathPrefix("/api").Subrouter()
+	api.HandleFunc("/files/{path...}", handler).Methods(http.MethodPut).Metadata(OperationID, "putFile")
+
+	doc, err := Generate(r, Options{Title: "Test", Version: "1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	want := []string{"/api/files/{path}", "/reports", "/reports/{year}", "/search", "/users/{id}"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected paths %v, got %v", want, paths)
+	}
+	for _, p := range want {
+		if doc.Paths[p] == nil {
+			t.Errorf("Expected path %q, got %v", p, paths)
+		}
+	}
+
+	user := doc.Paths["/users/{id}"]
+	if user.Get == nil || user.Delete == nil || user.Post != nil {
+		t.Fatalf("Unexpected operations for /users/{id}: %+v", user)
+	}
+	wantGet := &Operation{
+		OperationID: "user_get",
+		Summary:     "A user",
+		Tags:        []string{"users"},
+		Parameters: []Parameter{
+			{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}},
+		},
+		Responses: map[string]Response{"default": {Description: "Default response"}},
+	}
+	if !reflect.DeepEqual(user.Get, wantGet) {
+		t.Errorf("Expected operation %+v, got %+v", wantGet, user.Get)
+	}
+
+	search := doc.Paths["/search"].Get
+	zero := 0
+	wantParams := []Parameter{
+		{Name: "q", In: "query", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "debug", In: "query", Required: true, Schema: &Schema{Type: "string", Enum: []string{"1"}}},
+		{Name: "limit", In: "query", Required: true, Schema: &Schema{Type: "integer", Minimum: &zero, Default: 10}},
+	}
+	if search == nil || !reflect.DeepEqual(search.Parameters, wantParams) {
+		t.Errorf("Expected query parameters %+v, got %+v", wantParams, search)
+	}
+
+	year := doc.Paths["/reports/{year}"].Get
+	if !year.Deprecated || year.Parameters[0].Schema.Pattern != "^[0-9]{4}$" {
+		t.Errorf("Unexpected operation for /reports/{year}: %+v", year)
+	}
+	if doc.Paths["/reports"].Get.Parameters != nil {
+		t.Error("Expected no parameters for /reports")
+	}
+
+	files := doc.Paths["/api/files/{path}"]
+	wantServers := []Server{{
+		URL:       "https://{tenant}.example.com",
+		Variables: map[string]ServerVariable{"tenant": {Default: "tenant", Pattern: "^[a-z]+$"}},
+	}}
+	if files.Put == nil || files.Put.OperationID != "putFile" || !reflect.DeepEqual(files.Put.Servers, wantServers) {
+		t.Errorf("Unexpected path item for /api/files/{path}: %+v", files)
+	}
+}
+
+func TestGenerateRouteError(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/{a}", handler).Name("bad").Host("{a}")
+	if _, err := Generate(r, Options{}); err == nil || !strings.Contains(err.Error(), `route "bad"`) {
+		t.Errorf("Expected an error for the invalid route, got %v", err)
+	}
+}
+
+func TestDocumentEncoding(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/items/{id}", handler).Methods(http.MethodGet).Metadata(Tags, []string{"a", "b"})
+	doc, err := Generate(r, Options{Title: "Items", Version: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Document
+	if err := json.Unmarshal(b, &decoded); err != nil || !reflect.DeepEqual(&decoded, doc) {
+		t.Errorf("Expected the JSON to round-trip, got %s", b)
+	}
+
+	y, err := doc.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `openapi: "3.1.0"
+info:
+  title: "Items"
+  version: "2"
+paths:
+  "/items/{id}":
+    get:
+      tags:
+        - "a"
+        - "b"
+      parameters:
+        - name: "id"
+          in: "path"
+          required: true
+          schema:
+            type: "string"
+      responses:
+        default:
+          description: "Default response"
+`
+	if string(y) != want {
+		t.Errorf("Expected YAML:\n%s\ngot:\n%s", want, y)
+	}
+}
+
+func TestGenerateServers(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/items", handler).Host("a.example.com").Methods(http.MethodGet)
+	r.HandleFunc("/items", handler).Host("b.example.com").Schemes("https").Methods(http.MethodPost)
+	r.HandleFunc("/items", handler).Methods(http.MethodDelete)
+	doc, err := Generate(r, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := doc.Paths["/items"]
+	if items == nil || items.Get == nil || items.Post == nil || items.Delete == nil {
+		t.Fatalf("Unexpected path item %+v", items)
+	}
+	if want := []Server{{URL: "http://a.example.com"}}; !reflect.DeepEqual(items.Get.Servers, want) {
+		t.Errorf("Expected servers %+v for GET, got %+v", want, items.Get.Servers)
+	}
+	if want := []Server{{URL: "https://b.example.com"}}; !reflect.DeepEqual(items.Post.Servers, want) {
+		t.Errorf("Expected servers %+v for POST, got %+v", want, items.Post.Servers)
+	}
+	if items.Delete.Servers != nil {
+		t.Errorf("Expected no servers for DELETE, got %+v", items.Delete.Servers)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// YAML returns the document encoded as YAML.
+func (d *Document) YAML() ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	// Decode the JSON tokens to keep the order of the fields.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	n, err := decodeNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	n.writeYAML(&buf, "")
+	return buf.Bytes(), nil
+}
+
+// node is a decoded JSON value with ordered object keys.
+type node struct {
+	// For objects.
+	keys []string
+	// For objects and arrays.
+	values   []*node
+	isObject bool
+	isArray  bool
+	// For other values, encoded as JSON.
+	scalar string
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &node{}
+	switch tok := tok.(type) {
+	case json.Delim:
+		n.isObject, n.isArray = tok == '{', tok == '['
+		for dec.More() {
+			if n.isObject {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			v, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, v)
+		}
+		// Closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	default:
+		// JSON strings, numbers, booleans and null are valid YAML scalars.
+		b, err := json.Marshal(tok)
+		if err != nil {
+			return nil, err
+		}
+		n.scalar = string(b)
+	}
+	return n, nil
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// writeYAML writes the node as a block, each line prefixed with indent.
+func (n *node) writeYAML(buf *bytes.Buffer, indent string) {
+	switch {
+	case n.isObject:
+		for i, key := range n.keys {
+			if !plainKey.MatchString(key) {
+				b, _ := json.Marshal(key)
+				key = string(b)
+			}
+			buf.WriteString(indent + key + ":")
+			n.values[i].writeValue(buf, indent+"  ")
+		}
+	case n.isArray:
+		for _, v := range n.values {
+			if (v.isObject || v.isArray) && len(v.values) > 0 {
+				// Write the first line after the dash.
+				var item bytes.Buffer
+				v.writeYAML(&item, indent+"  ")
+				buf.WriteString(indent + "- " + strings.TrimPrefix(item.String(), indent+"  "))
+				continue
+			}
+			buf.WriteString(indent + "-")
+			v.writeValue(buf, indent+"  ")
+		}
+	}
+}
+
+// writeValue writes the node after a key or a dash.
+func (n *node) writeValue(buf *bytes.Buffer, indent string) {
+	switch {
+	case n.isObject && len(n.values) == 0:
+		buf.WriteString(" {}\n")
+	case n.isArray && len(n.values) == 0:
+		buf.WriteString(" []\n")
+	case n.isObject || n.isArray:
+		buf.WriteString("\n")
+		n.writeYAML(buf, indent)
+	default:
+		buf.WriteString(" " + n.scalar + "\n")
+	}
+}
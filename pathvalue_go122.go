@@ -0,0 +1,20 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.22 && !go1.23
+
+package mux
+
+import "net/http"
+
+// requestWithPathValues returns a shallow copy of the request with the route
+// variables set as path values. Request.Pattern was added in Go 1.23. See
+// Router.PopulatePathValues.
+func requestWithPathValues(r *http.Request, _ *Route, vars map[string]string) *http.Request {
+	r = r.WithContext(r.Context())
+	for name, value := range vars {
+		r.SetPathValue(name, value)
+	}
+	return r
+}
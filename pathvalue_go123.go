@@ -0,0 +1,33 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package mux
+
+import "net/http"
+
+// requestWithPathValues returns a shallow copy of the request with the route
+// variables set as path values and the route templates set as its pattern.
+// See Router.PopulatePathValues.
+func requestWithPathValues(r *http.Request, route *Route, vars map[string]string) *http.Request {
+	r = r.WithContext(r.Context())
+	for name, value := range vars {
+		r.SetPathValue(name, value)
+	}
+	r.Pattern = routePattern(route)
+	return r
+}
+
+// routePattern returns the host and path templates of a route.
+func routePattern(route *Route) string {
+	var pattern string
+	if route.regexp.host != nil {
+		pattern = route.regexp.host.template
+	}
+	if route.regexp.path != nil {
+		pattern += route.regexp.path.template
+	}
+	return pattern
+}
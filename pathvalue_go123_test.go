@@ -0,0 +1,44 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPopulatePathValuesPattern(t *testing.T) {
+	var pattern string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		pattern = r.Pattern
+	}
+
+	router := NewRouter().PopulatePathValues(true)
+	router.HandleFunc("/items/{id:int}", handler)
+	api := router.Host("{tenant}.example.com").PathPrefix("/api").Subrouter()
+	api.HandleFunc("/files/{id}/{rest...}", handler)
+	plain := NewRouter()
+	plain.HandleFunc("/items/{id}", handler)
+
+	tests := []struct {
+		router  *Router
+		url     string
+		pattern string
+	}{
+		{router, "http://localhost/items/42", "/items/{id:int}"},
+		{router, "http://acme.example.com/api/files/7/a/b.txt", "{tenant}.example.com/api/files/{id}/{rest...}"},
+		{plain, "http://localhost/items/42", ""},
+	}
+	for _, tt := range tests {
+		pattern = ""
+		tt.router.ServeHTTP(httptest.NewRecorder(), newRequest(http.MethodGet, tt.url))
+		if pattern != tt.pattern {
+			t.Errorf("%s: expected %q, got %q", tt.url, tt.pattern, pattern)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.22
+
+package mux
+
+import "net/http"
+
+// requestWithPathValues returns the request unchanged: Request.SetPathValue
+// was added in Go 1.22. See Router.PopulatePathValues.
+func requestWithPathValues(r *http.Request, _ *Route, _ map[string]string) *http.Request {
+	return r
+}
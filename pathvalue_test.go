@@ -0,0 +1,45 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.22
+
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPopulatePathValues(t *testing.T) {
+	var id, rest string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		id, rest = r.PathValue("id"), r.PathValue("rest")
+	}
+
+	router := NewRouter().PopulatePathValues(true)
+	router.HandleFunc("/items/{id:int}", handler)
+	api := router.Host("{tenant}.example.com").PathPrefix("/api").Subrouter()
+	api.HandleFunc("/files/{id}/{rest...}", handler)
+	plain := NewRouter()
+	plain.HandleFunc("/items/{id}", handler)
+
+	tests := []struct {
+		router *Router
+		url    string
+		id     string
+		rest   string
+	}{
+		{router, "http://localhost/items/42", "42", ""},
+		{router, "http://acme.example.com/api/files/7/a/b.txt", "7", "a/b.txt"},
+		{plain, "http://localhost/items/42", "", ""},
+	}
+	for _, tt := range tests {
+		id, rest = "", ""
+		tt.router.ServeHTTP(httptest.NewRecorder(), newRequest(http.MethodGet, tt.url))
+		if id != tt.id || rest != tt.rest {
+			t.Errorf("%s: expected %q %q, got %q %q", tt.url, tt.id, tt.rest, id, rest)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/http"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// routeIndex is a prefix tree over the path segments of a router's routes.
+//
+// It is used to narrow down the routes that can possibly match a request
+// before running their matchers, so the cost of a lookup depends on the depth
+// of the path rather than the number of routes. The index only ever discards
+// routes that are certain to fail, the remaining candidates are still matched
+// in the order they were registered.
+type routeIndex struct {
+	// Generation of the routes indexed, used to detect a stale index.
+	gen uint64
+	// Tree for routes matching the decoded path.
+	decoded *indexNode
+	// Tree for routes matching the encoded path, see Router.UseEncodedPath.
+	encoded *indexNode
+	// clearing[i] is the number of routes before i which, when skipped, would
+	// have cleared an ErrNotFound left in the match by a previous route.
+	clearing []int
+}
+
+// indexNode is a node of the routeIndex tree. The root node represents the
+// leading slash of a path, every other node a path segment.
+type indexNode struct {
+	// Child nodes for literal segments.
+	children map[string]*indexNode
+	// Child node for segments with variables.
+	param *indexNode
+	// Routes with a path template that ends at this node.
+	leaves []int
+	// Routes that may match any path below this node.
+	prefixes []indexPrefix
+}
+
+// indexPrefix is a route that may match any path below an indexNode as long
+// as the remaining path starts with lit.
+type indexPrefix struct {
+	lit   string
+	route int
+}
+
+// newRouteIndex builds an index for the given routes.
+func newRouteIndex(routes []*Route, gen uint64) *routeIndex {
+	idx := &routeIndex{
+		gen:      gen,
+		decoded:  &indexNode{},
+		clearing: make([]int, len(routes)+1),
+	}
+	for i, route := range routes {
+		idx.clearing[i+1] = idx.clearing[i]
+		if !route.buildOnly && route.err == nil {
+			// Disabled routes are counted too, see Route.Match.
+			idx.clearing[i+1]++
+		}
+		rr := route.indexedPath()
+		if rr == nil {
+			idx.decoded.prefixes = append(idx.decoded.prefixes, indexPrefix{route: i})
+			continue
+		}
+		root := idx.decoded
+		if rr.options.useEncodedPath {
+			if idx.encoded == nil {
+				idx.encoded = &indexNode{}
+			}
+			root = idx.encoded
+		}
+		root.insert(rr, i)
+	}
+	return idx
+}
+
+// lookup returns the indexes of the routes that may match the request, in
+// the order the routes were registered.
+func (idx *routeIndex) lookup(req *http.Request) []int {
+	routes := idx.decoded.lookupPath(req.URL.Path, nil)
+	if idx.encoded != nil {
+		routes = idx.encoded.lookupPath(req.URL.EscapedPath(), routes)
+	}
+	sort.Ints(routes)
+	return routes
+}
+
+// skipClears reports whether skipping the routes between the indexes i and j,
+// exclusive, would have cleared an ErrNotFound left by a previous route.
+func (idx *routeIndex) skipClears(i, j int) bool {
+	return idx.clearing[j]-idx.clearing[i+1] > 0
+}
+
+// insert adds the route at index i with the given path matcher to the tree.
+func (n *indexNode) insert(rr *routeRegexp, i int) {
+	tpl := rr.template
+	prefix := rr.regexpType == regexpTypePrefix
+	if len(rr.groups) > 0 {
+		// Only the part before the first optional part is always there.
+		tpl, prefix = tpl[:optionalIndex(tpl)], true
+	}
+	if tpl == "" || tpl[0] != '/' {
+		n.prefixes = append(n.prefixes, indexPrefix{route: i})
+		return
+	}
+	segments := splitTemplate(tpl[1:], '/')
+	if !prefix && len(segments) > 0 && segments[len(segments)-1] == "" {
+		// A trailing slash is optional in the tree, so strict slash routes
+		// are found for both forms.
+		segments = segments[:len(segments)-1]
+	}
+	varIdx := 0
+	for k, seg := range segments {
+		lit, vars, ok := parseSegment(seg, rr.varsR[varIdx:])
+		if !ok || (prefix && k == len(segments)-1) {
+			// The remaining path can't be split into segments, or this is
+			// the partial last segment of a prefix.
+			n.prefixes = append(n.prefixes, indexPrefix{lit: lit, route: i})
+			return
+		}
+		varIdx += vars
+		if vars > 0 {
+			if n.param == nil {
+				n.param = &indexNode{}
+			}
+			n = n.param
+			continue
+		}
+		child := n.children[seg]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[string]*indexNode)
+			}
+			child = &indexNode{}
+			n.children[seg] = child
+		}
+		n = child
+	}
+	n.leaves = append(n.leaves, i)
+}
+
+// lookupPath appends to routes the routes of the tree that may match path.
+func (n *indexNode) lookupPath(path string, routes []int) []int {
+	if path == "" || path[0] != '/' {
+		// Only routes which don't index a path may match.
+		return n.lookup("", false, routes)
+	}
+	return n.lookup(path[1:], true, routes)
+}
+
+// lookup appends to routes the routes below the node that may match the
+// remaining path. more is false when no segment is left.
+func (n *indexNode) lookup(path string, more bool, routes []int) []int {
+	for _, p := range n.prefixes {
+		if p.lit == "" || (more && strings.HasPrefix(path, p.lit)) {
+			routes = append(routes, p.route)
+		}
+	}
+	if !more || path == "" {
+		routes = append(routes, n.leaves...)
+	}
+	if !more {
+		return routes
+	}
+	seg, rest, next := path, "", false
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		seg, rest, next = path[:i], path[i+1:], true
+	}
+	if child := n.children[seg]; child != nil {
+		routes = child.lookup(rest, next, routes)
+	}
+	if n.param != nil {
+		routes = n.param.lookup(rest, next, routes)
+	}
+	return routes
+}
+
+// parseSegment inspects a template segment given the validators of the
+// variables starting at this segment. It returns the literal text before the
+// first variable, the number of variables in the segment and whether the
+// segment can be indexed, which is not the case if a variable may match a
+// slash.
+func parseSegment(seg string, varsR []*regexp.Regexp) (lit string, vars int, ok bool) {
+	idxs, err := braceIndices(seg)
+	if err != nil {
+		return "", 0, false
+	}
+	if len(idxs) == 0 {
+		return seg, 0, true
+	}
+	lit = seg[:idxs[0]]
+	vars = len(idxs) / 2
+	if vars > len(varsR) {
+		return lit, 0, false
+	}
+	for _, v := range varsR[:vars] {
+		if patternMatchesSlash(v.String()) {
+			return lit, 0, false
+		}
+	}
+	return lit, vars, true
+}
+
+// patternMatchesSlash reports whether the regular expression may match a
+// string containing a slash. It errs on the side of true.
+func patternMatchesSlash(expr string) bool {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return true
+	}
+	return syntaxMatchesSlash(re.Simplify())
+}
+
+func syntaxMatchesSlash(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '/' {
+				return true
+			}
+		}
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '/' && '/' <= re.Rune[i+1] {
+				return true
+			}
+		}
+	}
+	for _, sub := range re.Sub {
+		if syntaxMatchesSlash(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeIndexCache holds the index of a router, rebuilt when routes are added
+// or removed.
+type routeIndexCache struct {
+	mu  sync.Mutex
+	idx atomic.Pointer[routeIndex]
+}
+
+// get returns an up to date index for the given routes and generation, see
+// Router.routeList.
+func (c *routeIndexCache) get(routes []*Route, gen uint64) *routeIndex {
+	if idx := c.idx.Load(); idx != nil && idx.gen == gen {
+		return idx
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx := c.idx.Load()
+	if idx == nil || idx.gen != gen {
+		idx = newRouteIndex(routes, gen)
+		c.idx.Store(idx)
+	}
+	return idx
+}
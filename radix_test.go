@@ -0,0 +1,194 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// radixTestRouter builds the same set of routes on a router with and
+// without radix matching.
+func radixTestRouter(radix bool) *Router {
+	r := NewRouter().RadixMatching(radix).OptionalParts(true)
+	r.HandleFunc("/", stringHandler("root"))
+	r.HandleFunc("/users", stringHandler("users")).Methods(http.MethodGet)
+	r.HandleFunc("/users", stringHandler("users-post")).Methods(http.MethodPost)
+	r.HandleFunc("/users/{id:[0-9]+}", stringHandler("user"))
+	r.HandleFunc("/users/me", stringHandler("me"))
+	r.HandleFunc("/users/{id}/posts/{post}", stringHandler("post")).Methods(http.MethodGet)
+	r.HandleFunc("/files/{path:.*}", stringHandler("file"))
+	r.HandleFunc("/docs/{lang}/{page...}", stringHandler("doc"))
+	r.HandleFunc("/reports[/{year:[0-9]{4}}][.{format=json}]", stringHandler("report"))
+	r.HandleFunc("/news[/{year:[0-9]{4}}]/list", stringHandler("news"))
+	r.HandleFunc("/img/{name}.{ext:png|jpg}", stringHandler("img"))
+	r.HandleFunc("/search", stringHandler("search")).Queries("q", "{q}")
+	r.Queries("debug", "1").Path("/users/{id}").HandlerFunc(stringHandler("debug"))
+	r.HandleFunc("/host", stringHandler("host")).Host("{sub}.example.com")
+	r.Host("api.example.com").HandlerFunc(stringHandler("api-host"))
+	r.PathPrefix("/static").HandlerFunc(stringHandler("static"))
+
+	s := r.PathPrefix("/api/").Subrouter()
+	s.HandleFunc("/v1/items", stringHandler("items")).Methods(http.MethodGet)
+	s.HandleFunc("/v1/items/{id}", stringHandler("item")).Methods(http.MethodPut)
+	s.HandleFunc("/v2/", stringHandler("v2"))
+
+	strict := r.NewRoute().Subrouter().StrictSlash(true)
+	strict.HandleFunc("/strict/", stringHandler("strict"))
+	strict.HandleFunc("/slash", stringHandler("slash"))
+
+	enc := r.NewRoute().Subrouter().UseEncodedPath()
+	enc.HandleFunc("/enc/{name}/x", stringHandler("enc"))
+
+	r.HandleFunc("/built", stringHandler("built")).BuildOnly()
+	r.MatcherFunc(func(req *http.Request, _ *RouteMatch) bool {
+		return req.Header.Get("X-Any") != ""
+	}).HandlerFunc(stringHandler("any"))
+	return r
+}
+
+func TestRadixMatching(t *testing.T) {
+	requests := []*http.Request{
+		newRequest(http.MethodGet, "http://localhost/"),
+		newRequest(http.MethodGet, "http://localhost/users"),
+		newRequest(http.MethodPost, "http://localhost/users"),
+		newRequest(http.MethodDelete, "http://localhost/users"),
+		newRequest(http.MethodGet, "http://localhost/users/"),
+		newRequest(http.MethodGet, "http://localhost/users/42"),
+		newRequest(http.MethodGet, "http://localhost/users/me"),
+		newRequest(http.MethodGet, "http://localhost/users/abc"),
+		newRequest(http.MethodGet, "http://localhost/users/abc?debug=1"),
+		newRequest(http.MethodGet, "http://localhost/users/1/posts/2"),
+		newRequest(http.MethodPost, "http://localhost/users/1/posts/2"),
+		newRequest(http.MethodGet, "http://localhost/files/"),
+		newRequest(http.MethodGet, "http://localhost/files/a/b/c.txt"),
+		newRequest(http.MethodGet, "http://localhost/docs/en/"),
+		newRequest(http.MethodGet, "http://localhost/docs/en/guide/intro"),
+		newRequest(http.MethodGet, "http://localhost/docs/en"),
+		newRequest(http.MethodGet, "http://localhost/reports"),
+		newRequest(http.MethodGet, "http://localhost/reports/2024"),
+		newRequest(http.MethodGet, "http://localhost/reports/2024.csv"),
+		newRequest(http.MethodGet, "http://localhost/news/list"),
+		newRequest(http.MethodGet, "http://localhost/news/2024/list"),
+		newRequest(http.MethodGet, "http://localhost/img/logo.png"),
+		newRequest(http.MethodGet, "http://localhost/img/logo.gif"),
+		newRequest(http.MethodGet, "http://localhost/search?q=mux"),
+		newRequest(http.MethodGet, "http://localhost/search"),
+		newRequest(http.MethodGet, "http://www.example.com/host"),
+		newRequest(http.MethodGet, "http://api.example.com/whatever"),
+		newRequest(http.MethodGet, "http://localhost/static"),
+		newRequest(http.MethodGet, "http://localhost/staticfiles/app.js"),
+		newRequest(http.MethodGet, "http://localhost/api/v1/items"),
+		newRequest(http.MethodPost, "http://localhost/api/v1/items"),
+		newRequest(http.MethodPut, "http://localhost/api/v1/items/3"),
+		newRequest(http.MethodGet, "http://localhost/api/v1/items/3"),
+		newRequest(http.MethodGet, "http://localhost/api/v2/"),
+		newRequest(http.MethodGet, "http://localhost/api/v3"),
+		newRequest(http.MethodGet, "http://localhost/strict"),
+		newRequest(http.MethodGet, "http://localhost/strict/"),
+		newRequest(http.MethodGet, "http://localhost/slash/"),
+		newRequest(http.MethodGet, "http://localhost/enc/a%2Fb/x"),
+		newRequest(http.MethodGet, "http://localhost/built"),
+		newRequestWithHeaders(http.MethodGet, "http://localhost/nowhere", "X-Any", "1"),
+		newRequest(http.MethodGet, "http://localhost/nowhere"),
+	}
+
+	linear, radix := radixTestRouter(false), radixTestRouter(true)
+	for _, req := range requests {
+		t.Run(req.Method+" "+req.URL.String(), func(t *testing.T) {
+			var want, got RouteMatch
+			wantOK := linear.Match(req, &want)
+			gotOK := radix.Match(req, &got)
+			if gotOK != wantOK {
+				t.Fatalf("Match returned %v, want %v", gotOK, wantOK)
+			}
+			if got.MatchErr != want.MatchErr {
+				t.Errorf("MatchErr is %v, want %v", got.MatchErr, want.MatchErr)
+			}
+			if !reflect.DeepEqual(got.Vars, want.Vars) {
+				t.Errorf("Vars are %v, want %v", got.Vars, want.Vars)
+			}
+			if got.Route != nil && want.Route != nil {
+				if getRouteTemplate(got.Route) != getRouteTemplate(want.Route) {
+					t.Errorf("Route is %v, want %v", getRouteTemplate(got.Route), getRouteTemplate(want.Route))
+				}
+			} else if (got.Route == nil) != (want.Route == nil) {
+				t.Errorf("Route is %v, want %v", got.Route, want.Route)
+			}
+			if (got.Handler == nil) != (want.Handler == nil) {
+				t.Errorf("Handler is %v, want %v", got.Handler, want.Handler)
+			}
+
+			wantRec, gotRec := NewRecorder(), NewRecorder()
+			linear.ServeHTTP(wantRec, req)
+			radix.ServeHTTP(gotRec, req)
+			if gotRec.Code != wantRec.Code || gotRec.Body.String() != wantRec.Body.String() {
+				t.Errorf("Response is %d %q, want %d %q", gotRec.Code, gotRec.Body.String(), wantRec.Code, wantRec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRadixMatchingRoutesAdded(t *testing.T) {
+	r := NewRouter().RadixMatching(true)
+	r.HandleFunc("/a", stringHandler("a"))
+
+	req := newRequest(http.MethodGet, "http://localhost/b")
+	var match RouteMatch
+	if r.Match(req, &match) {
+		t.Fatal("Expected no match before the route is added")
+	}
+
+	r.HandleFunc("/b", stringHandler("b"))
+	match = RouteMatch{}
+	if !r.Match(req, &match) {
+		t.Fatal("Expected a match after the route is added")
+	}
+}
+
+func TestRadixMatchingSubrouterInherits(t *testing.T) {
+	r := NewRouter().RadixMatching(true)
+	s := r.PathPrefix("/api").Subrouter()
+	if !s.radixMatching || s.index == nil {
+		t.Fatal("Expected the subrouter to inherit radix matching")
+	}
+}
+
+func TestPatternMatchesSlash(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"[^/]+", false},
+		{"[0-9]+", false},
+		{"(?:png|jpg)", false},
+		{".*", true},
+		{"[^a]+", true},
+		{"a/b", true},
+		{"[a-z/]+", true},
+		{"(", true},
+	}
+	for _, tt := range tests {
+		if got := patternMatchesSlash(tt.pattern); got != tt.want {
+			t.Errorf("patternMatchesSlash(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func ExampleRouter_RadixMatching() {
+	r := NewRouter().RadixMatching(true)
+	for i := 0; i < 1000; i++ {
+		r.HandleFunc(fmt.Sprintf("/resource%d/{id:[0-9]+}", i), func(http.ResponseWriter, *http.Request) {})
+	}
+
+	var match RouteMatch
+	req, _ := http.NewRequest(http.MethodGet, "/resource999/42", nil)
+	if r.Match(req, &match) {
+		fmt.Println(match.Vars["id"])
+	}
+	// Output: 42
+}
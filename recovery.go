@@ -0,0 +1,204 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicReport describes a panic recovered by RecoveryMiddleware.
+type PanicReport struct {
+	// The value passed to panic.
+	Value any
+	// The stack trace of the goroutine that panicked.
+	Stack []byte
+	// The request being served, with the matched route and its variables
+	// in its context.
+	Request *http.Request
+	// The name and path template of the matched route, empty if none.
+	RouteName    string
+	PathTemplate string
+	// The route variables, see Vars.
+	Vars map[string]string
+}
+
+// String formats the report with its stack trace, for logs.
+func (p PanicReport) String() string {
+	route := p.PathTemplate
+	if p.RouteName != "" {
+		route = fmt.Sprintf("%s (%s)", p.RouteName, p.PathTemplate)
+	}
+	return fmt.Sprintf("mux: panic serving %s %s, route %s, vars %v: %v\n%s",
+		p.Request.Method, p.Request.URL, route, p.Vars, p.Value, p.Stack)
+}
+
+// PanicReporter receives the panics recovered by RecoveryMiddleware.
+// ReportPanic may be called concurrently.
+type PanicReporter interface {
+	ReportPanic(report PanicReport)
+}
+
+// PanicReporterFunc is an adapter to use a function as a PanicReporter.
+type PanicReporterFunc func(report PanicReport)
+
+// ReportPanic calls f(report).
+func (f PanicReporterFunc) ReportPanic(report PanicReport) {
+	f(report)
+}
+
+// RecoveryOptions configures RecoveryMiddleware.
+type RecoveryOptions struct {
+	// Receives the panics. If nil, they are written to the standard logger.
+	Reporter PanicReporter
+	// Writes the response after a panic, unless the handler already wrote
+	// the response headers. If nil, the response is a plain 500 Internal
+	// Server Error.
+	Handler http.Handler
+}
+
+// RecoveryMiddleware returns a middleware recovering from panics in the
+// handlers it wraps, for Router.Use. It reports each panic with the matched
+// route and its variables, then writes the response with opts.Handler unless
+// the headers were already sent.
+//
+// Panics with the value http.ErrAbortHandler are not recovered, so that they
+// abort the response as net/http documents.
+func RecoveryMiddleware(opts RecoveryOptions) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rw := &recoveryResponseWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				report := PanicReport{
+					Value:   v,
+					Stack:   debug.Stack(),
+					Request: req,
+					Vars:    Vars(req),
+				}
+				if route := CurrentRoute(req); route != nil {
+					report.RouteName = route.GetName()
+					report.PathTemplate, _ = route.GetPathTemplate()
+				}
+				if opts.Reporter != nil {
+					opts.Reporter.ReportPanic(report)
+				} else {
+					log.Print(report)
+				}
+				if rw.wroteHeader {
+					return
+				}
+				if opts.Handler != nil {
+					opts.Handler.ServeHTTP(w, req)
+				} else {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// recoveryResponseWriter records whether the response headers were sent.
+type recoveryResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *recoveryResponseWriter) WriteHeader(code int) {
+	// Informational responses don't send the final headers.
+	if code >= 200 {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recoveryResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recoveryResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying ResponseWriter does. No
+// response is written on a hijacked connection if the handler panics.
+func (w *recoveryResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	w.wroteHeader = true
+	return h.Hijack()
+}
+
+// Push implements http.Pusher if the underlying ResponseWriter does.
+func (w *recoveryResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w *recoveryResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// PanicRecorder is a PanicReporter that records the panics, for instance to
+// check in tests that no route panicked:
+//
+//	var panics mux.PanicRecorder
+//	r.Use(mux.RecoveryMiddleware(mux.RecoveryOptions{Reporter: &panics}))
+//	// ...
+//	if err := panics.Err(); err != nil {
+//	    t.Fatal(err)
+//	}
+//
+// The zero value is ready to use.
+type PanicRecorder struct {
+	mu      sync.Mutex
+	reports []PanicReport
+}
+
+// ReportPanic records the report.
+func (r *PanicRecorder) ReportPanic(report PanicReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+}
+
+// Reports returns the reports recorded so far.
+func (r *PanicRecorder) Reports() []PanicReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]PanicReport(nil), r.reports...)
+}
+
+// Err returns an error describing the first panic recorded, or nil if there
+// were none.
+func (r *PanicRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.reports) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d panics recovered, the first one: %s", len(r.reports), r.reports[0])
+}
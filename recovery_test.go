@@ -0,0 +1,166 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	var panics PanicRecorder
+	router := NewRouter()
+	router.Use(RecoveryMiddleware(RecoveryOptions{Reporter: &panics}))
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}).Name("user")
+	router.HandleFunc("/partial", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late boom")
+	})
+	router.HandleFunc("/ok", stringHandler("ok"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodGet, "/ok"))
+	if w.Body.String() != "ok" {
+		t.Errorf("Expected the handler to be called, got %q", w.Body.String())
+	}
+	if err := panics.Err(); err != nil {
+		t.Fatalf("Expected no panics, got %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodGet, "/users/42"))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code 500, got %d", w.Code)
+	}
+	reports := panics.Reports()
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 panic, got %d", len(reports))
+	}
+	p := reports[0]
+	if p.Value != "boom" || p.RouteName != "user" || p.PathTemplate != "/users/{id}" ||
+		!reflect.DeepEqual(p.Vars, map[string]string{"id": "42"}) || p.Request == nil {
+		t.Errorf("Unexpected report %+v", p)
+	}
+	if !strings.Contains(string(p.Stack), "recovery_test.go") {
+		t.Errorf("Expected the stack to include the handler, got:\n%s", p.Stack)
+	}
+	if err := panics.Err(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected an error describing the panic, got %v", err)
+	}
+
+	// The headers were sent: the response is left as is.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodGet, "/partial"))
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("Expected the response to be left as is, got %d %q", w.Code, w.Body.String())
+	}
+	if len(panics.Reports()) != 2 {
+		t.Errorf("Expected 2 panics, got %d", len(panics.Reports()))
+	}
+}
+
+func TestRecoveryMiddlewareOptions(t *testing.T) {
+	var reported any
+	router := NewRouter()
+	router.Use(RecoveryMiddleware(RecoveryOptions{
+		Reporter: PanicReporterFunc(func(p PanicReport) { reported = p.Value }),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "oops", http.StatusServiceUnavailable)
+		}),
+	}))
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		panic(42)
+	})
+	router.HandleFunc("/abort", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(http.MethodGet, "/"))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "oops\n" {
+		t.Errorf("Expected the custom response, got %d %q", w.Code, w.Body.String())
+	}
+	if reported != 42 {
+		t.Errorf("Expected the panic to be reported, got %v", reported)
+	}
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to be re-raised, got %v", v)
+		}
+	}()
+	router.ServeHTTP(httptest.NewRecorder(), newRequest(http.MethodGet, "/abort"))
+}
+
+// hijackRecorder is a ResponseRecorder implementing http.Hijacker and
+// http.Pusher.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	pushed   []string
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	c, _ := net.Pipe()
+	return c, bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c)), nil
+}
+
+func (w *hijackRecorder) Push(target string, _ *http.PushOptions) error {
+	w.pushed = append(w.pushed, target)
+	return nil
+}
+
+func TestRecoveryMiddlewareInterfaces(t *testing.T) {
+	router := NewRouter()
+	router.Use(RecoveryMiddleware(RecoveryOptions{Reporter: PanicReporterFunc(func(PanicReport) {})}))
+	router.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("Expected the response writer to implement http.Flusher")
+		}
+		p, ok := w.(http.Pusher)
+		if !ok {
+			t.Fatal("Expected the response writer to implement http.Pusher")
+		}
+		if err := p.Push("/app.js", nil); err != nil {
+			t.Errorf("Unexpected push error %v", err)
+		}
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("Expected the response writer to implement http.Hijacker")
+		}
+		conn, _, err := h.Hijack()
+		if err != nil {
+			t.Fatalf("Unexpected hijack error %v", err)
+		}
+		conn.Close()
+		panic("after hijack")
+	})
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, newRequest(http.MethodGet, "/ws"))
+	if !w.hijacked || !reflect.DeepEqual(w.pushed, []string{"/app.js"}) {
+		t.Errorf("Expected the calls to reach the response writer, got %v %v", w.hijacked, w.pushed)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected no response on the hijacked connection, got %q", w.Body.String())
+	}
+
+	// Writers without these interfaces report them as not supported.
+	rw := &recoveryResponseWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rw.Hijack(); err != http.ErrNotSupported {
+		t.Errorf("Expected http.ErrNotSupported, got %v", err)
+	}
+	if err := rw.Push("/app.js", nil); err != http.ErrNotSupported {
+		t.Errorf("Expected http.ErrNotSupported, got %v", err)
+	}
+}
@@ -17,6 +17,11 @@ import (
 type routeRegexpOptions struct {
 	strictSlash    bool
 	useEncodedPath bool
+	// If true, path templates can have optional parts and variables default
+	// values, see Router.OptionalParts.
+	optionalParts bool
+	// Named patterns, see Router.RegisterPattern.
+	patterns map[string]string
 }
 
 type regexpType int
@@ -28,6 +33,18 @@ const (
 	regexpTypeQuery
 )
 
+// varTypePatterns maps the type shortcuts accepted as variable patterns, as
+// in {id:int}, to the regexps they expand to.
+var varTypePatterns = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"slug":  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+	"date":  `[0-9]{4}-(?:0[1-9]|1[0-2])-(?:0[1-9]|[12][0-9]|3[01])`,
+	"hex":   `[0-9a-fA-F]+`,
+	"alpha": `[a-zA-Z]+`,
+}
+
 // newRouteRegexp parses a route template and returns a routeRegexp,
 // used to match a host, a path or a query string.
 //
@@ -38,6 +55,12 @@ const (
 // Previously we accepted only Python-like identifiers for variable
 // names ([a-zA-Z_][a-zA-Z0-9_]*), but currently the only restriction is that
 // name and pattern can't be empty, and names can't contain a colon.
+//
+// A path template can end with a wildcard variable, {name...}, matching the
+// rest of the path. With the optionalParts option, it can have optional parts
+// enclosed in square brackets, as in /reports[/{year}], and a variable can
+// have a default value, {name=value} or {name=value:pattern}, used when it
+// isn't matched or given to build a URL.
 func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*routeRegexp, error) {
 	// Check if it is well-formed.
 	idxs, errBraces := braceIndices(tpl)
@@ -53,8 +76,11 @@ func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*ro
 	} else if typ == regexpTypeHost {
 		defaultPattern = "[^.]+"
 	}
+	// A trailing wildcard matches the rest of the path, slashes included.
+	wildcard := typ == regexpTypePath && len(idxs) > 0 && idxs[len(idxs)-1] == len(tpl) &&
+		isWildcardTag(tpl[idxs[len(idxs)-2]:])
 	// Only match strict slash if not matching
-	if typ != regexpTypePath {
+	if typ != regexpTypePath || wildcard {
 		options.strictSlash = false
 	}
 	// Set a flag for strictSlash.
@@ -65,10 +91,45 @@ func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*ro
 	}
 	varsN := make([]string, len(idxs)/2)
 	varsR := make([]*regexp.Regexp, len(idxs)/2)
+	varsT := make([]string, len(idxs)/2)
+	var defaults map[string]string
 
 	var pattern, reverse strings.Builder
 	pattern.WriteByte('^')
 
+	// Optional parts are only supported in paths: hosts can contain
+	// brackets, as in [::1].
+	var groups []optionalGroup
+	inGroup := false
+	writeRaw := func(raw string) error {
+		for options.optionalParts && (typ == regexpTypePath || typ == regexpTypePrefix) {
+			i := strings.IndexAny(raw, "[]")
+			if i == -1 {
+				break
+			}
+			pattern.WriteString(regexp.QuoteMeta(raw[:i]))
+			reverse.WriteString(raw[:i])
+			if raw[i] == '[' {
+				if inGroup {
+					return fmt.Errorf("mux: nested optional parts in %q", template)
+				}
+				pattern.WriteString("(?:")
+				groups = append(groups, optionalGroup{start: reverse.Len()})
+			} else {
+				if !inGroup {
+					return fmt.Errorf("mux: unbalanced brackets in %q", template)
+				}
+				pattern.WriteString(")?")
+				groups[len(groups)-1].end = reverse.Len()
+			}
+			inGroup = !inGroup
+			raw = raw[i+1:]
+		}
+		pattern.WriteString(regexp.QuoteMeta(raw))
+		reverse.WriteString(raw)
+		return nil
+	}
+
 	var end, colonIdx, groupIdx int
 	var err error
 	var patt, param, name string
@@ -84,25 +145,51 @@ func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*ro
 		param = tag[1 : len(tag)-1]
 
 		colonIdx = strings.Index(param, ":")
-		if colonIdx == -1 {
+		if isWildcardTag(tag) {
+			if !wildcard || i+2 < len(idxs) {
+				return nil, fmt.Errorf("mux: wildcard %q must be at the end of a path template", tag)
+			}
+			name = strings.TrimSuffix(param, "...")
+			patt = ".*"
+		} else if colonIdx == -1 {
 			name = param
 			patt = defaultPattern
 		} else {
 			name = param[0:colonIdx]
 			patt = param[colonIdx+1:]
 		}
+		var def string
+		hasDefault := false
+		if eqIdx := strings.Index(name, "="); eqIdx != -1 && options.optionalParts {
+			name, def, hasDefault = name[:eqIdx], name[eqIdx+1:], true
+		}
 
 		// Name or pattern can't be empty.
 		if name == "" || patt == "" {
 			return nil, fmt.Errorf("mux: missing name or pattern in %q", tag)
 		}
+		// Expand type shortcuts such as {id:int} and named patterns such
+		// as {tenant:@tenant}.
+		if typePatt, ok := varTypePatterns[patt]; ok {
+			varsT[groupIdx] = patt
+			patt = typePatt
+		} else if namedPatt, ok := namedPattern(patt, options.patterns); ok {
+			patt = namedPatt
+		}
 		// Build the regexp pattern.
 		groupName := varGroupName(groupIdx)
 
-		pattern.WriteString(regexp.QuoteMeta(raw) + "(?P<" + groupName + ">" + patt + ")")
+		if err = writeRaw(raw); err != nil {
+			return nil, err
+		}
+		pattern.WriteString("(?P<" + groupName + ">" + patt + ")")
 
 		// Build the reverse template.
-		reverse.WriteString(raw + "%s")
+		reverse.WriteString("%s")
+		if inGroup {
+			g := &groups[len(groups)-1]
+			g.vars = append(g.vars, groupIdx)
+		}
 
 		// Append variable name and compiled pattern.
 		varsN[groupIdx] = name
@@ -110,10 +197,24 @@ func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*ro
 		if err != nil {
 			return nil, fmt.Errorf("mux: error compiling regex for %q: %w", tag, err)
 		}
+		if hasDefault {
+			if !varsR[groupIdx].MatchString(def) {
+				return nil, fmt.Errorf("mux: default value %q doesn't match the pattern in %q", def, tag)
+			}
+			if defaults == nil {
+				defaults = make(map[string]string)
+			}
+			defaults[name] = def
+		}
 	}
 	// Add the remaining.
 	raw := tpl[end:]
-	pattern.WriteString(regexp.QuoteMeta(raw))
+	if err = writeRaw(raw); err != nil {
+		return nil, err
+	}
+	if inGroup {
+		return nil, fmt.Errorf("mux: unbalanced brackets in %q", template)
+	}
 	if options.strictSlash {
 		pattern.WriteString("[/]?")
 	}
@@ -146,7 +247,6 @@ func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*ro
 			wildcardHostPort = true
 		}
 	}
-	reverse.WriteString(raw)
 	if endSlash {
 		reverse.WriteByte('/')
 	}
@@ -160,6 +260,10 @@ func newRouteRegexp(tpl string, typ regexpType, options routeRegexpOptions) (*ro
 		reverse:          reverse.String(),
 		varsN:            varsN,
 		varsR:            varsR,
+		varsT:            varsT,
+		defaults:         defaults,
+		groups:           groups,
+		wildcard:         wildcard,
 		wildcardHostPort: wildcardHostPort,
 	}, nil
 }
@@ -181,10 +285,26 @@ type routeRegexp struct {
 	varsN []string
 	// Variable regexps (validators).
 	varsR []*regexp.Regexp
+	// Variable types, for variables declared with a type shortcut.
+	varsT []string
+	// Default values of variables.
+	defaults map[string]string
+	// Optional parts of the template.
+	groups []optionalGroup
+	// Whether the last variable is a wildcard, see isWildcardTag.
+	wildcard bool
 	// Wildcard host-port (no strict port match in hostname)
 	wildcardHostPort bool
 }
 
+// optionalGroup is an optional part of a template, enclosed in brackets.
+type optionalGroup struct {
+	// Bounds of the part in the reverse template.
+	start, end int
+	// Indexes of the variables in the part.
+	vars []int
+}
+
 // Match matches the regexp against the URL host or path.
 func (r *routeRegexp) Match(req *http.Request, match *RouteMatch) bool {
 	if r.regexpType == regexpTypeHost {
@@ -209,27 +329,60 @@ func (r *routeRegexp) Match(req *http.Request, match *RouteMatch) bool {
 }
 
 // url builds a URL part using the given values.
+//
+// Optional parts are left out when any of their variables is missing,
+// otherwise missing variables take their default value.
 func (r *routeRegexp) url(values map[string]string) (string, error) {
-	urlValues := make([]interface{}, len(r.varsN))
+	reverse := r.reverse
+	var omitted []bool
+	for i := len(r.groups) - 1; i >= 0; i-- {
+		g := r.groups[i]
+		for _, k := range g.vars {
+			if _, ok := values[r.varsN[k]]; !ok {
+				if omitted == nil {
+					omitted = make([]bool, len(r.varsN))
+				}
+				for _, k := range g.vars {
+					omitted[k] = true
+				}
+				reverse = reverse[:g.start] + reverse[g.end:]
+				break
+			}
+		}
+	}
+	urlValues := make([]interface{}, 0, len(r.varsN))
+	used := make(map[string]string, len(r.varsN))
 	for k, v := range r.varsN {
+		if omitted != nil && omitted[k] {
+			continue
+		}
 		value, ok := values[v]
 		if !ok {
-			return "", fmt.Errorf("mux: missing route variable %q", v)
+			if value, ok = r.defaults[v]; !ok {
+				return "", fmt.Errorf("mux: missing route variable %q", v)
+			}
 		}
+		used[v] = value
 		if r.regexpType == regexpTypeQuery {
 			value = url.QueryEscape(value)
+		} else if r.wildcard {
+			value = r.escapePathValue(value, k == len(r.varsN)-1)
 		}
-		urlValues[k] = value
+		urlValues = append(urlValues, value)
 	}
-	rv := fmt.Sprintf(r.reverse, urlValues...)
-	if !r.regexp.MatchString(rv) {
+	rv := fmt.Sprintf(reverse, urlValues...)
+	check := rv
+	if r.wildcard && !r.options.useEncodedPath {
+		check, _ = url.PathUnescape(rv)
+	}
+	if !r.regexp.MatchString(check) {
 		// The URL is checked against the full regexp, instead of checking
 		// individual variables. This is faster but to provide a good error
 		// message, we check individual regexps if the URL doesn't match.
 		for k, v := range r.varsN {
-			if !r.varsR[k].MatchString(values[v]) {
+			if value, ok := used[v]; ok && !r.varsR[k].MatchString(value) {
 				return "", fmt.Errorf(
-					"mux: variable %q doesn't match, expected %q", values[v],
+					"mux: variable %q doesn't match, expected %q", value,
 					r.varsR[k].String())
 			}
 		}
@@ -237,6 +390,113 @@ func (r *routeRegexp) url(values map[string]string) (string, error) {
 	return rv, nil
 }
 
+// escapePathValue escapes a variable value for a path with a wildcard, which
+// is built escaped, see routeRegexp.setURLPath. The wildcard value is escaped
+// segment by segment. Values are already escaped when the router uses encoded
+// paths.
+func (r *routeRegexp) escapePathValue(value string, wildcard bool) string {
+	if !wildcard {
+		if r.options.useEncodedPath {
+			return value
+		}
+		return url.PathEscape(value)
+	}
+	segments := strings.Split(value, "/")
+	for i, s := range segments {
+		if r.options.useEncodedPath {
+			if u, err := url.PathUnescape(s); err == nil {
+				s = u
+			}
+		}
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// setURLPath sets the path of a URL built with the regexp. Paths with a
+// wildcard are built escaped, to preserve the segments of the wildcard value.
+func (r *routeRegexp) setURLPath(u *url.URL, path string) {
+	if !r.wildcard {
+		u.Path = path
+		return
+	}
+	if p, err := url.PathUnescape(path); err == nil {
+		u.Path = p
+		if u.EscapedPath() != path {
+			u.RawPath = path
+		}
+	} else {
+		u.Path = path
+	}
+}
+
+// isOptional reports whether the variable at index i is in an optional part.
+func (r *routeRegexp) isOptional(i int) bool {
+	for _, g := range r.groups {
+		for _, k := range g.vars {
+			if k == i {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// variants returns the template with its variables written as {name}, once
+// per combination of its optional parts, see Route.GetPathVariants.
+func (r *routeRegexp) variants() []TemplateVariant {
+	variants := make([]TemplateVariant, 0, 1<<len(r.groups))
+	for mask := 0; mask < 1<<len(r.groups); mask++ {
+		var b strings.Builder
+		var names []string
+		// The index of the next optional part and of the next variable.
+		g, k := 0, 0
+		for i := 0; i < len(r.reverse); {
+			if g < len(r.groups) && i == r.groups[g].start {
+				if mask&(1<<g) == 0 {
+					i = r.groups[g].end
+					k += len(r.groups[g].vars)
+				}
+				g++
+				continue
+			}
+			if strings.HasPrefix(r.reverse[i:], "%s") {
+				b.WriteString("{" + r.varsN[k] + "}")
+				names = append(names, r.varsN[k])
+				k++
+				i += 2
+				continue
+			}
+			b.WriteByte(r.reverse[i])
+			i++
+		}
+		variants = append(variants, TemplateVariant{Template: b.String(), Vars: names})
+	}
+	return variants
+}
+
+// isWildcardTag reports whether a template variable is a wildcard, {name...}.
+func isWildcardTag(tag string) bool {
+	return strings.HasSuffix(tag, "...}") && !strings.Contains(tag, ":")
+}
+
+// appendVars appends the variables of the regexp to vars.
+func (r *routeRegexp) appendVars(vars []RouteVar) []RouteVar {
+	for i, name := range r.varsN {
+		pattern := strings.TrimSuffix(strings.TrimPrefix(r.varsR[i].String(), "^"), "$")
+		vars = append(vars, RouteVar{
+			Name:     name,
+			Matcher:  r.matcherKind(),
+			Pattern:  pattern,
+			Type:     r.varsT[i],
+			Wildcard: r.wildcard && i == len(r.varsN)-1,
+			Optional: r.isOptional(i),
+			Default:  r.defaults[name],
+		})
+	}
+	return vars
+}
+
 // getURLQuery returns a single query parameter from a request URL.
 // For a URL with foo=bar&baz=ding, we return only the relevant key
 // value pair for the routeRegexp.
@@ -319,6 +579,58 @@ func braceIndices(s string) ([]int, error) {
 	return idxs, nil
 }
 
+// optionalIndex returns the index of the first optional part of a path
+// template, or -1.
+func optionalIndex(tpl string) int {
+	var level int
+	for i := 0; i < len(tpl); i++ {
+		switch tpl[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+		case '[':
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTemplate splits a template on the separators which are not part of a
+// variable.
+func splitTemplate(tpl string, sep byte) []string {
+	var segments []string
+	var level, start int
+	for i := 0; i < len(tpl); i++ {
+		switch tpl[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+		case sep:
+			if level == 0 {
+				segments = append(segments, tpl[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, tpl[start:])
+}
+
+// namedPattern returns the registered pattern a variable pattern such as
+// @sha refers to, see Router.RegisterPattern. Other patterns starting with @,
+// such as @[a-z]+, are regexps as they were before named patterns.
+func namedPattern(patt string, patterns map[string]string) (string, bool) {
+	name, ok := strings.CutPrefix(patt, "@")
+	if !ok || !isIdentifier(name) {
+		return "", false
+	}
+	namedPatt, ok := patterns[name]
+	return namedPatt, ok
+}
+
 // varGroupName builds a capturing group name for the indexed variable.
 func varGroupName(idx int) string {
 	return "v" + strconv.Itoa(idx)
@@ -349,7 +661,7 @@ func (v routeRegexpGroup) setMatch(req *http.Request, m *RouteMatch, r *Route) {
 			}
 			matches := v.host.regexp.FindStringSubmatchIndex(host)
 			if len(matches) > 0 {
-				m.Vars = extractVars(host, matches, v.host.varsN, m.Vars)
+				m.Vars = extractVars(host, matches, v.host.varsN, v.host.defaults, m.Vars)
 			}
 		}
 	}
@@ -362,7 +674,7 @@ func (v routeRegexpGroup) setMatch(req *http.Request, m *RouteMatch, r *Route) {
 		if len(v.path.varsN) > 0 {
 			matches := v.path.regexp.FindStringSubmatchIndex(path)
 			if len(matches) > 0 {
-				m.Vars = extractVars(path, matches, v.path.varsN, m.Vars)
+				m.Vars = extractVars(path, matches, v.path.varsN, v.path.defaults, m.Vars)
 			}
 		}
 		// Check if we should redirect.
@@ -387,7 +699,7 @@ func (v routeRegexpGroup) setMatch(req *http.Request, m *RouteMatch, r *Route) {
 			queryURL := q.getURLQuery(req)
 			matches := q.regexp.FindStringSubmatchIndex(queryURL)
 			if len(matches) > 0 {
-				m.Vars = extractVars(queryURL, matches, q.varsN, m.Vars)
+				m.Vars = extractVars(queryURL, matches, q.varsN, q.defaults, m.Vars)
 			}
 		}
 	}
@@ -403,11 +715,19 @@ func getHost(r *http.Request) string {
 	return r.Host
 }
 
-func extractVars(input string, matches []int, names []string, output map[string]string) map[string]string {
+// extractVars adds the variables matched in input to output. Variables of
+// optional parts that didn't match take their default value, if any.
+func extractVars(input string, matches []int, names []string, defaults map[string]string, output map[string]string) map[string]string {
 	for i, name := range names {
 		if output == nil {
 			output = make(map[string]string, len(names))
 		}
+		if matches[2*i+2] < 0 {
+			if def, ok := defaults[name]; ok {
+				output[name] = def
+			}
+			continue
+		}
 		output[name] = input[matches[2*i+2]:matches[2*i+3]]
 	}
 	return output
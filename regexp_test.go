@@ -33,6 +33,56 @@ func Test_newRouteRegexp_Errors(t *testing.T) {
 	}
 }
 
+func TestRegisterPattern(t *testing.T) {
+	r := NewRouter().RegisterPattern("tenant", "[a-z][a-z0-9-]{2,30}")
+	s := r.PathPrefix("/{tenant:@tenant}").Subrouter().RegisterPattern("sha", "[0-9a-f]{40}")
+	route := s.HandleFunc("/commits/{commit:@sha}", dummyHandler).Queries("owner", "{owner:@tenant}")
+	if err := route.GetError(); err != nil {
+		t.Fatal(err)
+	}
+	sha := strings.Repeat("ab", 20)
+
+	var match RouteMatch
+	if !r.Match(newRequest("GET", "http://localhost/acme/commits/"+sha+"?owner=bob"), &match) {
+		t.Fatal("Expected a match")
+	}
+	if want := map[string]string{"tenant": "acme", "commit": sha, "owner": "bob"}; !reflect.DeepEqual(match.Vars, want) {
+		t.Errorf("Expected vars %v, got %v", want, match.Vars)
+	}
+	if r.Match(newRequest("GET", "http://localhost/a/commits/"+sha+"?owner=bob"), &RouteMatch{}) {
+		t.Error("Expected the tenant pattern to reject a short tenant")
+	}
+	if _, err := route.URL("tenant", "acme", "commit", "xyz", "owner", "bob"); err == nil {
+		t.Error("Expected URL building to validate against the pattern")
+	}
+
+	// Patterns registered on a subrouter don't leak to its parent: unknown
+	// names are regexps.
+	other := r.HandleFunc("/{commit:@sha}", dummyHandler)
+	if err := other.GetError(); err != nil {
+		t.Fatal(err)
+	}
+	if !other.Match(newRequest("GET", "http://localhost/@sha"), &RouteMatch{}) ||
+		other.Match(newRequest("GET", "http://localhost/"+sha), &RouteMatch{}) {
+		t.Error("Expected an unknown pattern name to match literally")
+	}
+}
+
+func TestAtPrefixedRegexp(t *testing.T) {
+	r := NewRouter().RegisterPattern("h", "[0-9]+")
+	route := r.HandleFunc("/u/{h:@[a-z]+}", dummyHandler)
+	if err := route.GetError(); err != nil {
+		t.Fatal(err)
+	}
+	var match RouteMatch
+	if !r.Match(newRequest("GET", "http://localhost/u/@bob"), &match) || match.Vars["h"] != "@bob" {
+		t.Errorf("Expected a match with h=@bob, got %v %v", match.MatchErr, match.Vars)
+	}
+	if r.Match(newRequest("GET", "http://localhost/u/42"), &RouteMatch{}) {
+		t.Error("Expected the regexp not to be replaced by a registered pattern")
+	}
+}
+
 func Test_findFirstQueryKey(t *testing.T) {
 	tests := []string{
 		"a=1&b=2",
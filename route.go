@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync/atomic"
 )
 
 // Route stores information to match a request and build URLs.
@@ -19,10 +20,19 @@ type Route struct {
 	handler http.Handler
 	// If true, this route never matches: it is only used to build URLs.
 	buildOnly bool
+	// If true, this route never matches until it is enabled again.
+	disabled atomic.Bool
+	// If true, the route was registered with Router.Mount.
+	mount bool
+	// If true, the router of the route was frozen: matchers can no longer be
+	// added, see Router.Freeze.
+	frozen bool
 	// The name used to build URLs.
 	name string
 	// Error resulted from building a route.
 	err error
+	// The conflict with an earlier route, if any, see Router.RejectConflicts.
+	conflict atomic.Pointer[RouteConflict]
 
 	// The meta data associated with this route
 	metadata map[any]any
@@ -33,6 +43,10 @@ type Route struct {
 	// route specific middleware
 	middlewares []middleware
 
+	// Middlewares used instead of the inherited middlewares with the same
+	// name, nil to skip them. See Route.Without and Route.Replace.
+	middlewareOverrides map[string]middleware
+
 	// config possibly passed in from `Router`
 	routeConf
 }
@@ -48,14 +62,23 @@ func (r *Route) Match(req *http.Request, match *RouteMatch) bool {
 	if r.buildOnly || r.err != nil {
 		return false
 	}
+	if r.disabled.Load() || r.conflict.Load() != nil {
+		// Fail like a route whose matchers don't match, see below.
+		if match.MatchErr == ErrNotFound {
+			match.MatchErr = nil
+		}
+		return false
+	}
 
 	var matchErr error
+	var methods methodMatcher
 
 	// Match everything.
 	for _, m := range r.matchers {
 		if matched := m.Match(req, match); !matched {
-			if _, ok := m.(methodMatcher); ok {
+			if mm, ok := m.(methodMatcher); ok {
 				matchErr = ErrMethodMismatch
+				methods = mm
 				continue
 			}
 
@@ -90,12 +113,20 @@ func (r *Route) Match(req *http.Request, match *RouteMatch) bool {
 
 	if matchErr != nil {
 		match.MatchErr = matchErr
+		if matchErr == ErrMethodMismatch {
+			for _, method := range methods {
+				if !matchInArray(match.AllowedMethods, method) {
+					match.AllowedMethods = append(match.AllowedMethods, method)
+				}
+			}
+		}
 		return false
 	}
 
 	if match.MatchErr != nil && r.handler != nil {
 		// We found a route which matches request method, clear MatchErr
 		match.MatchErr = nil
+		match.AllowedMethods = nil
 		// Then override the mis-matched handler
 		match.Handler = r.handler
 	}
@@ -117,9 +148,16 @@ func (r *Route) Match(req *http.Request, match *RouteMatch) bool {
 // Route attributes
 // ----------------------------------------------------------------------------
 
-// GetError returns an error resulted from building the route, if any.
+// GetError returns an error resulted from building the route, if any, or
+// the *RouteConflict rejecting it, see Router.RejectConflicts.
 func (r *Route) GetError() error {
-	return r.err
+	if r.err != nil {
+		return r.err
+	}
+	if c := r.conflict.Load(); c != nil {
+		return c
+	}
+	return nil
 }
 
 // BuildOnly sets the route to never match: it is only used to build URLs.
@@ -128,6 +166,31 @@ func (r *Route) BuildOnly() *Route {
 	return r
 }
 
+// IsBuildOnly returns true if the route is only used to build URLs.
+func (r *Route) IsBuildOnly() bool {
+	return r.buildOnly
+}
+
+// Disable sets the route to never match, as if it was removed from its
+// router, until Enable is called. The route can still be used to build URLs.
+// Unlike other route settings, it is safe to call while the router serves
+// requests, for instance to turn a feature-flagged endpoint off.
+func (r *Route) Disable() *Route {
+	r.disabled.Store(true)
+	return r
+}
+
+// Enable sets a route disabled by Disable to match again.
+func (r *Route) Enable() *Route {
+	r.disabled.Store(false)
+	return r
+}
+
+// IsDisabled reports whether the route was disabled with Disable.
+func (r *Route) IsDisabled() bool {
+	return r.disabled.Load()
+}
+
 // MetaData -------------------------------------------------------------------
 
 // Metadata is used to set metadata on a route
@@ -216,7 +279,9 @@ func (r *Route) Name(name string) *Route {
 	}
 	if r.err == nil {
 		r.name = name
+		namedRoutesMu.Lock()
 		r.namedRoutes[name] = r
+		namedRoutesMu.Unlock()
 	}
 	return r
 }
@@ -235,19 +300,44 @@ type matcher interface {
 	Match(*http.Request, *RouteMatch) bool
 }
 
-// addMatcher adds a matcher to the route.
+// addMatcher adds a matcher to the route, unless the route is frozen.
 func (r *Route) addMatcher(m matcher) *Route {
-	if r.err == nil {
+	if r.err == nil && !r.frozen {
 		r.matchers = append(r.matchers, m)
 	}
 	return r
 }
 
+// indexedPath returns the first path matcher of the route if every matcher
+// before it is free of side effects. Such a route fails the same way no
+// matter which of its matchers rejected the request, so it can be skipped
+// when its path can't match. It returns nil otherwise.
+func (r *Route) indexedPath() *routeRegexp {
+	for _, m := range r.matchers {
+		switch m := m.(type) {
+		case methodMatcher, headerMatcher, headerRegexMatcher, schemeMatcher:
+			continue
+		case *routeRegexp:
+			switch m.regexpType {
+			case regexpTypePath, regexpTypePrefix:
+				return m
+			case regexpTypeHost:
+				continue
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
 // addRegexpMatcher adds a host or path matcher and builder to a route.
 func (r *Route) addRegexpMatcher(tpl string, typ regexpType) error {
 	if r.err != nil {
 		return r.err
 	}
+	if r.frozen {
+		return nil
+	}
 	if typ == regexpTypePath || typ == regexpTypePrefix {
 		if len(tpl) > 0 && tpl[0] != '/' {
 			return fmt.Errorf("mux: path must start with a slash, got %q", tpl)
@@ -259,6 +349,8 @@ func (r *Route) addRegexpMatcher(tpl string, typ regexpType) error {
 	rr, err := newRouteRegexp(tpl, typ, routeRegexpOptions{
 		strictSlash:    r.strictSlash,
 		useEncodedPath: r.useEncodedPath,
+		optionalParts:  r.optionalParts,
+		patterns:       r.patterns,
 	})
 	if err != nil {
 		return err
@@ -554,9 +646,19 @@ func (r *Route) BuildVarsFunc(f BuildVarsFunc) *Route {
 //
 // Here, the routes registered in the subrouter won't be tested if the host
 // doesn't match.
+//
+// The subrouter of a route of a frozen router is frozen and never tested, see
+// Router.Freeze.
 func (r *Route) Subrouter() *Router {
 	// initialize a subrouter with a copy of the parent route's configuration
 	router := &Router{routeConf: copyRouteConf(r.routeConf), namedRoutes: r.namedRoutes}
+	if r.frozen {
+		router.frozen = true
+		return router
+	}
+	if router.radixMatching {
+		router.index = &routeIndexCache{}
+	}
 	r.addMatcher(router)
 	return router
 }
@@ -611,6 +713,12 @@ func (r *Route) URL(pairs ...string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
+	return r.buildURL(values)
+}
+
+// buildURL builds the URL of the route from the values of its variables.
+func (r *Route) buildURL(values map[string]string) (*url.URL, error) {
+	var err error
 	var scheme, host, path string
 	queries := make([]string, 0, len(r.regexp.queries))
 	if r.regexp.host != nil {
@@ -634,12 +742,15 @@ func (r *Route) URL(pairs ...string) (*url.URL, error) {
 		}
 		queries = append(queries, query)
 	}
-	return &url.URL{
+	u := &url.URL{
 		Scheme:   scheme,
 		Host:     host,
-		Path:     path,
 		RawQuery: strings.Join(queries, "&"),
-	}, nil
+	}
+	if r.regexp.path != nil {
+		r.regexp.path.setURLPath(u, path)
+	}
+	return u, nil
 }
 
 // URLHost builds the host part of the URL for a route. See Route.URL().
@@ -688,9 +799,9 @@ func (r *Route) URLPath(pairs ...string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &url.URL{
-		Path: path,
-	}, nil
+	u := &url.URL{}
+	r.regexp.path.setURLPath(u, path)
+	return u, nil
 }
 
 // GetPathTemplate returns the template used to build the
@@ -708,6 +819,31 @@ func (r *Route) GetPathTemplate() (string, error) {
 	return r.regexp.path.template, nil
 }
 
+// TemplateVariant is a template of a route with its variables written as
+// {name}, without pattern, default value or wildcard suffix, as in
+// "/users/{id}" for "/users/{id:[0-9]+}". See Route.GetPathVariants.
+type TemplateVariant struct {
+	Template string
+	// The names of the variables, in the order of the template.
+	Vars []string
+}
+
+// GetPathVariants returns the path template of the route as template
+// variants, one per combination of its optional parts, see
+// Router.OptionalParts. Optional parts are all left out in the first variant
+// and all included in the last one.
+// This is useful to generate API documentation without parsing templates.
+// An error will be returned if the route does not define a path.
+func (r *Route) GetPathVariants() ([]TemplateVariant, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.regexp.path == nil {
+		return nil, errors.New("mux: route doesn't have a path")
+	}
+	return r.regexp.path.variants(), nil
+}
+
 // GetPathRegexp returns the expanded regular expression used to match route path.
 // This is useful for building simple REST API documentation and for instrumentation
 // against third-party services.
@@ -726,7 +862,7 @@ func (r *Route) GetPathRegexp() (string, error) {
 // route queries.
 // This is useful for building simple REST API documentation and for instrumentation
 // against third-party services.
-// An error will be returned if the route does not have queries.
+// An error will be returned if the route does not define queries.
 func (r *Route) GetQueriesRegexp() ([]string, error) {
 	if r.err != nil {
 		return nil, r.err
@@ -760,6 +896,23 @@ func (r *Route) GetQueriesTemplates() ([]string, error) {
 	return queries, nil
 }
 
+// GetQueriesVariants returns the query templates of the route as template
+// variants, as in "page={page}", in the same order as GetQueriesTemplates.
+// An error will be returned if the route does not define queries.
+func (r *Route) GetQueriesVariants() ([]TemplateVariant, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.regexp.queries == nil {
+		return nil, errors.New("mux: route doesn't have queries")
+	}
+	var variants []TemplateVariant
+	for _, query := range r.regexp.queries {
+		variants = append(variants, query.variants()...)
+	}
+	return variants, nil
+}
+
 // GetMethods returns the methods the route matches against
 // This is useful for building simple REST API documentation and for instrumentation
 // against third-party services.
@@ -776,6 +929,22 @@ func (r *Route) GetMethods() ([]string, error) {
 	return nil, errors.New("mux: route doesn't have methods")
 }
 
+// GetSchemes returns the schemes the route matches against
+// This is useful for building simple REST API documentation and for instrumentation
+// against third-party services.
+// An error will be returned if route does not have schemes.
+func (r *Route) GetSchemes() ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, m := range r.matchers {
+		if schemes, ok := m.(schemeMatcher); ok {
+			return []string(schemes), nil
+		}
+	}
+	return nil, errors.New("mux: route doesn't have schemes")
+}
+
 // GetHostTemplate returns the template used to build the
 // route match.
 // This is useful for building simple REST API documentation and for instrumentation
@@ -791,6 +960,19 @@ func (r *Route) GetHostTemplate() (string, error) {
 	return r.regexp.host.template, nil
 }
 
+// GetHostVariant returns the host template of the route as a template
+// variant, as in "{subdomain}.example.com".
+// An error will be returned if the route does not define a host.
+func (r *Route) GetHostVariant() (TemplateVariant, error) {
+	if r.err != nil {
+		return TemplateVariant{}, r.err
+	}
+	if r.regexp.host == nil {
+		return TemplateVariant{}, errors.New("mux: route doesn't have a host")
+	}
+	return r.regexp.host.variants()[0], nil
+}
+
 // GetVarNames returns the names of all variables added by regexp matchers
 // These can be used to know which route variables should be passed into r.URL()
 func (r *Route) GetVarNames() ([]string, error) {
@@ -810,6 +992,108 @@ func (r *Route) GetVarNames() ([]string, error) {
 	return varNames, nil
 }
 
+// RouteVar describes a route variable. See Route.GetVars.
+type RouteVar struct {
+	Name string
+	// The matcher the variable belongs to: MatcherHost, MatcherPath,
+	// MatcherPathPrefix or MatcherQuery.
+	Matcher MatcherKind
+	// The regexp the variable values must match.
+	Pattern string
+	// The type shortcut the variable was declared with, such as "int" for
+	// {id:int}, or an empty string.
+	Type string
+	// Whether the variable is a wildcard matching the rest of the path, as
+	// in {path...}.
+	Wildcard bool
+	// Whether the variable is in an optional part of the path, as in
+	// /reports[/{year}].
+	Optional bool
+	// The default value of the variable, as in {format=json}, or an empty
+	// string.
+	Default string
+}
+
+// GetVars returns the variables added by regexp matchers, in the same order
+// as GetVarNames, with their patterns and types.
+func (r *Route) GetVars() ([]RouteVar, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var vars []RouteVar
+	if r.regexp.host != nil {
+		vars = r.regexp.host.appendVars(vars)
+	}
+	if r.regexp.path != nil {
+		vars = r.regexp.path.appendVars(vars)
+	}
+	for _, regx := range r.regexp.queries {
+		vars = regx.appendVars(vars)
+	}
+	return vars, nil
+}
+
+// subrouters returns the subrouters of the route: the routers created with
+// Subrouter and a router used as the route handler.
+func (r *Route) subrouters() []*Router {
+	var routers []*Router
+	for _, m := range r.matchers {
+		if h, ok := m.(*Router); ok {
+			routers = append(routers, h)
+		}
+	}
+	if h, ok := r.handler.(*Router); ok {
+		routers = append(routers, h)
+	}
+	return routers
+}
+
+// describe returns a short description of the route for error messages: its
+// name if it has one, its templates otherwise.
+func (r *Route) describe() string {
+	if r.name != "" {
+		return fmt.Sprintf("%q", r.name)
+	}
+	var tpl string
+	if r.regexp.host != nil {
+		tpl = r.regexp.host.template
+	}
+	if r.regexp.path != nil {
+		tpl += r.regexp.path.template
+	}
+	if tpl == "" {
+		return "(no path)"
+	}
+	return fmt.Sprintf("%q", tpl)
+}
+
+// summary returns the methods and templates of the route for error messages,
+// e.g. "GET example.com/users/{id}?page={page}".
+func (r *Route) summary() string {
+	var b strings.Builder
+	if methods, err := r.GetMethods(); err == nil {
+		b.WriteString(strings.Join(methods, ",") + " ")
+	}
+	if r.regexp.host != nil {
+		b.WriteString(r.regexp.host.template)
+	}
+	if r.regexp.path != nil {
+		b.WriteString(r.regexp.path.template)
+	}
+	for i, q := range r.regexp.queries {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(q.template)
+	}
+	if b.Len() == 0 {
+		return "(no path)"
+	}
+	return fmt.Sprintf("%q", b.String())
+}
+
 // prepareVars converts the route variable pairs into a map. If the route has a
 // BuildVarsFunc, it is invoked.
 func (r *Route) prepareVars(pairs ...string) (map[string]string, error) {
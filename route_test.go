@@ -152,3 +152,33 @@ func TestRouteMetadata(t *testing.T) {
 		router.ServeHTTP(rw, req)
 	})
 }
+
+func TestRouteDisable(t *testing.T) {
+	r := NewRouter()
+	route := r.HandleFunc("/a", stringHandler("a")).Name("a")
+	r.HandleFunc("/a", stringHandler("fallback"))
+
+	serve := func() string {
+		rec := NewRecorder()
+		r.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost/a"))
+		return rec.Body.String()
+	}
+
+	if body := serve(); body != "a" {
+		t.Fatalf("Expected body %q, got %q", "a", body)
+	}
+	route.Disable()
+	if !route.IsDisabled() {
+		t.Error("Expected the route to be disabled")
+	}
+	if body := serve(); body != "fallback" {
+		t.Errorf("Expected the disabled route to be skipped, got %q", body)
+	}
+	if u, err := r.Get("a").URL(); err != nil || u.Path != "/a" {
+		t.Errorf("Expected a disabled route to build URLs, got %v, %v", u, err)
+	}
+	route.Enable()
+	if body := serve(); body != "a" {
+		t.Errorf("Expected the enabled route to match again, got %q", body)
+	}
+}
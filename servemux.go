@@ -0,0 +1,269 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// HandlePattern registers a new route for a pattern in the syntax of
+// http.ServeMux since Go 1.22, "[METHOD ][HOST]/[PATH]", translated to the
+// equivalent matchers:
+//
+//   - The method is matched with Methods. As with ServeMux, GET also
+//     matches HEAD requests.
+//   - The host is matched with Host, regardless of the request port.
+//   - {name} and {name...} path segments are route variables, the latter
+//     matching the rest of the path. A path ending with a slash is matched
+//     with PathPrefix, unless it ends with {$}.
+//
+// For example:
+//
+//	r.HandlePattern("GET example.com/files/{id}/{path...}", handler)
+//
+// ...is equivalent to:
+//
+//	r.Host("example.com").Path("/files/{id}/{path...}").Methods("GET", "HEAD").Handler(handler)
+//
+// Invalid patterns result in a route with an error, see Route.GetError.
+// Unlike ServeMux, routes are matched in the order they are added rather
+// than by specificity, and a pattern ending with a slash doesn't redirect
+// the path without the trailing slash: "/images/" doesn't match "/images".
+// Brackets and equal signs in the pattern are matched literally, even if
+// the router has Router.OptionalParts enabled.
+func (r *Router) HandlePattern(pattern string, handler http.Handler) *Route {
+	route := r.NewRoute()
+	if route.err != nil {
+		return route
+	}
+	// ServeMux patterns have no optional parts or default values.
+	route.optionalParts = false
+	p, err := parseServeMuxPattern(pattern)
+	if err != nil {
+		route.err = fmt.Errorf("mux: invalid ServeMux pattern %q: %w", pattern, err)
+		return route
+	}
+	if p.host != "" {
+		route.Host(p.host)
+	}
+	if p.prefix {
+		route.PathPrefix(p.path)
+	} else {
+		route.Path(p.path)
+	}
+	switch p.method {
+	case "":
+	case http.MethodGet:
+		route.Methods(http.MethodGet, http.MethodHead)
+	default:
+		route.Methods(p.method)
+	}
+	return route.Handler(handler)
+}
+
+// HandlePatternFunc registers a new route for a ServeMux pattern with a
+// handler function. See HandlePattern.
+func (r *Router) HandlePatternFunc(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return r.HandlePattern(pattern, http.HandlerFunc(f))
+}
+
+// serveMuxPattern is a parsed ServeMux pattern.
+type serveMuxPattern struct {
+	method string
+	host   string
+	// The path as a mux template.
+	path   string
+	prefix bool
+}
+
+// parseServeMuxPattern parses a pattern in the syntax of http.ServeMux.
+func parseServeMuxPattern(s string) (serveMuxPattern, error) {
+	var p serveMuxPattern
+	rest := s
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		p.method, rest = s[:i], strings.TrimLeft(s[i+1:], " \t")
+		if strings.IndexFunc(p.method, func(r rune) bool { return r > unicode.MaxASCII || !unicode.IsPrint(r) }) >= 0 {
+			return p, fmt.Errorf("bad method %q", p.method)
+		}
+	}
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return p, errors.New("host/path missing /")
+	}
+	p.host = rest[:i]
+	if strings.Contains(p.host, "{") {
+		return p, errors.New("host contains '{' (missing initial '/'?)")
+	}
+
+	segments := strings.Split(rest[i+1:], "/")
+	seen := make(map[string]bool)
+	var path strings.Builder
+	for j, seg := range segments {
+		last := j == len(segments)-1
+		path.WriteByte('/')
+		if !strings.Contains(seg, "{") {
+			if strings.Contains(seg, "}") {
+				return p, fmt.Errorf("bad wildcard segment %q", seg)
+			}
+			path.WriteString(seg)
+			continue
+		}
+		if seg[0] != '{' || seg[len(seg)-1] != '}' {
+			return p, fmt.Errorf("bad wildcard segment %q (must be entire segment)", seg)
+		}
+		name := seg[1 : len(seg)-1]
+		if name == "$" {
+			if !last {
+				return p, errors.New("{$} not at end")
+			}
+			p.path = strings.TrimSuffix(path.String(), "/") + "/"
+			return p, nil
+		}
+		wildcard := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		if wildcard && !last {
+			return p, fmt.Errorf("{%s...} wildcard not at end", name)
+		}
+		if !isIdentifier(name) {
+			return p, fmt.Errorf("bad wildcard name %q", name)
+		}
+		if seen[name] {
+			return p, fmt.Errorf("duplicate wildcard name %q", name)
+		}
+		seen[name] = true
+		path.WriteString(seg)
+	}
+	p.path = path.String()
+	p.prefix = strings.HasSuffix(p.path, "/")
+	return p, nil
+}
+
+// isIdentifier reports whether s is a Go identifier, as ServeMux requires
+// for wildcard names.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeMuxPattern returns the route as a pattern in the syntax of
+// http.ServeMux since Go 1.22, the reverse of Router.HandlePattern. A route
+// with no path matches any path, like the "/" pattern.
+//
+// It returns an error listing the settings of the route that ServeMux
+// patterns can't express: host variables and ports, variables with custom
+// patterns, default values or not matching a whole path segment, optional
+// parts, path prefixes not ending with a slash, several methods, and
+// query, header, scheme and custom matchers.
+func (r *Route) ServeMuxPattern() (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	var problems []string
+	var method, host string
+	path := "/"
+	for _, m := range r.matchers {
+		switch m := m.(type) {
+		case *routeRegexp:
+			// Handled below from the regexps of the route.
+		case methodMatcher:
+			switch {
+			case len(m) == 1:
+				method = m[0]
+			case len(m) == 2 && matchInArray(m, http.MethodGet) && matchInArray(m, http.MethodHead):
+				method = http.MethodGet
+			default:
+				problems = append(problems, fmt.Sprintf("several methods %v", []string(m)))
+			}
+		case headerMatcher, headerRegexMatcher:
+			problems = append(problems, "header matcher")
+		case schemeMatcher:
+			problems = append(problems, "schemes matcher")
+		case *Router:
+			problems = append(problems, "subrouter")
+		default:
+			problems = append(problems, "custom matcher")
+		}
+	}
+	if rr := r.regexp.host; rr != nil {
+		host = rr.template
+		if len(rr.varsN) > 0 {
+			problems = append(problems, fmt.Sprintf("host variables in %q", rr.template))
+		} else if strings.Contains(host, ":") {
+			problems = append(problems, fmt.Sprintf("host port in %q", rr.template))
+		}
+	}
+	if rr := r.regexp.path; rr != nil {
+		var pathProblems []string
+		path, pathProblems = serveMuxPath(rr)
+		problems = append(problems, pathProblems...)
+	}
+	if len(r.regexp.queries) > 0 {
+		problems = append(problems, "query matcher")
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("mux: route %s can't be expressed as a ServeMux pattern: %s", r.describe(), strings.Join(problems, ", "))
+	}
+	pattern := host + path
+	if method != "" {
+		pattern = method + " " + pattern
+	}
+	return pattern, nil
+}
+
+// serveMuxPath converts a path template to the path of a ServeMux pattern,
+// and returns what can't be converted.
+func serveMuxPath(rr *routeRegexp) (string, []string) {
+	tpl := rr.template
+	var problems []string
+	if len(rr.groups) > 0 {
+		problems = append(problems, fmt.Sprintf("optional parts in %q", tpl))
+	}
+	idxs, _ := braceIndices(tpl)
+	var path strings.Builder
+	end := 0
+	for i := 0; i < len(idxs); i += 2 {
+		start, stop := idxs[i], idxs[i+1]
+		path.WriteString(tpl[end:start])
+		end = stop
+		tag := tpl[start:stop]
+		name := tag[1 : len(tag)-1]
+		if start == 0 || tpl[start-1] != '/' || (stop < len(tpl) && tpl[stop] != '/') {
+			problems = append(problems, fmt.Sprintf("variable %s not matching a whole path segment", tag))
+		}
+		switch {
+		case isWildcardTag(tag):
+			name = strings.TrimSuffix(name, "...")
+		case strings.Contains(name, ":"):
+			problems = append(problems, fmt.Sprintf("custom pattern for variable %s", tag))
+		case rr.options.optionalParts && strings.Contains(name, "="):
+			problems = append(problems, fmt.Sprintf("default value for variable %s", tag))
+			name = ""
+		}
+		if name != "" && !strings.Contains(name, ":") && !isIdentifier(name) {
+			problems = append(problems, fmt.Sprintf("variable name %q not an identifier", name))
+		}
+		path.WriteString(tag)
+	}
+	path.WriteString(tpl[end:])
+	p := path.String()
+	switch {
+	case rr.regexpType == regexpTypePrefix && !strings.HasSuffix(p, "/"):
+		problems = append(problems, fmt.Sprintf("path prefix %q not ending with a slash", tpl))
+	case rr.regexpType != regexpTypePrefix && strings.HasSuffix(p, "/"):
+		p += "{$}"
+	}
+	return p, problems
+}
@@ -0,0 +1,153 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandlePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		method  string
+		url     string
+		match   bool
+		vars    map[string]string
+	}{
+		{"/items/{id}", http.MethodPost, "http://localhost/items/42", true, map[string]string{"id": "42"}},
+		{"/items/{id}", http.MethodGet, "http://localhost/items/42/x", false, nil},
+		{"GET /items/{id}", http.MethodHead, "http://localhost/items/42", true, map[string]string{"id": "42"}},
+		{"GET /items/{id}", http.MethodPost, "http://localhost/items/42", false, nil},
+		{"DELETE  /items/{id}", http.MethodDelete, "http://localhost/items/42", true, map[string]string{"id": "42"}},
+		{"example.com/files/{path...}", http.MethodGet, "http://example.com:8080/files/a/b.txt", true, map[string]string{"path": "a/b.txt"}},
+		{"example.com/files/{path...}", http.MethodGet, "http://other.com/files/a/b.txt", false, nil},
+		{"/static/", http.MethodGet, "http://localhost/static/css/site.css", true, nil},
+		{"/static/", http.MethodGet, "http://localhost/static", false, nil},
+		{"/static/{$}", http.MethodGet, "http://localhost/static/", true, nil},
+		{"/static/{$}", http.MethodGet, "http://localhost/static/css", false, nil},
+		{"/{$}", http.MethodGet, "http://localhost/", true, nil},
+		{"/{$}", http.MethodGet, "http://localhost/x", false, nil},
+		{"/", http.MethodGet, "http://localhost/x/y", true, nil},
+	}
+	for _, tt := range tests {
+		r := NewRouter()
+		route := r.HandlePatternFunc(tt.pattern, dummyHandler)
+		if err := route.GetError(); err != nil {
+			t.Errorf("%q: unexpected error %v", tt.pattern, err)
+			continue
+		}
+		var match RouteMatch
+		matched := r.Match(newRequest(tt.method, tt.url), &match)
+		if matched != tt.match {
+			t.Errorf("%q: %s %s: expected match %v, got %v", tt.pattern, tt.method, tt.url, tt.match, matched)
+			continue
+		}
+		if matched && len(tt.vars) > 0 && !reflect.DeepEqual(match.Vars, tt.vars) {
+			t.Errorf("%q: %s: expected vars %v, got %v", tt.pattern, tt.url, tt.vars, match.Vars)
+		}
+	}
+}
+
+func TestHandlePatternLiterals(t *testing.T) {
+	r := NewRouter().OptionalParts(true)
+	route := r.HandlePatternFunc("GET /files/[draft]/a=b/{id}", dummyHandler)
+	if err := route.GetError(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	tests := []struct {
+		url   string
+		match bool
+	}{
+		{"http://localhost/files/%5Bdraft%5D/a=b/42", true},
+		{"http://localhost/files/a=b/42", false},
+		{"http://localhost/files/draft/a=b/42", false},
+	}
+	for _, tt := range tests {
+		var match RouteMatch
+		if matched := r.Match(newRequest(http.MethodGet, tt.url), &match); matched != tt.match {
+			t.Errorf("%s: expected match %v, got %v", tt.url, tt.match, matched)
+		}
+	}
+	if pattern, err := route.ServeMuxPattern(); err != nil || pattern != "GET /files/[draft]/a=b/{id}" {
+		t.Errorf("Expected the pattern back, got %q, %v", pattern, err)
+	}
+}
+
+func TestHandlePatternErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		err     string
+	}{
+		{"items", "host/path missing /"},
+		{"{host}/items", "host contains '{'"},
+		{"/items/a{id}", "must be entire segment"},
+		{"/items/{id}x", "must be entire segment"},
+		{"/items/{$}/x", "{$} not at end"},
+		{"/files/{path...}/x", "wildcard not at end"},
+		{"/items/{1d}", "bad wildcard name"},
+		{"/items/{id:[0-9]+}", "bad wildcard name"},
+		{"/items/{id}/{id}", "duplicate wildcard name"},
+	}
+	for _, tt := range tests {
+		err := NewRouter().HandlePattern(tt.pattern, http.NotFoundHandler()).GetError()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.pattern, tt.err, err)
+		}
+	}
+}
+
+func TestServeMuxPattern(t *testing.T) {
+	r := NewRouter()
+	opt := NewRouter().OptionalParts(true)
+	api := r.Host("example.com").PathPrefix("/api/").Subrouter()
+	tests := []struct {
+		route   *Route
+		pattern string
+		err     string
+	}{
+		{r.Path("/items/{id}").Methods(http.MethodPost), "POST /items/{id}", ""},
+		{r.Path("/items/{id}").Methods(http.MethodGet, http.MethodHead), "GET /items/{id}", ""},
+		{r.Path("/files/{path...}"), "/files/{path...}", ""},
+		{r.Path("/static/"), "/static/{$}", ""},
+		{r.PathPrefix("/static/"), "/static/", ""},
+		{r.Methods(http.MethodGet), "GET /", ""},
+		{api.Path("/items/{id}"), "example.com/api/items/{id}", ""},
+		{r.HandlePattern("GET example.com/files/{id}/{path...}", nil), "GET example.com/files/{id}/{path...}", ""},
+		{r.Path("/items/{id:[0-9]+}"), "", "custom pattern for variable {id:[0-9]+}"},
+		{r.Path("/items/{id:int}"), "", "custom pattern for variable {id:int}"},
+		{r.Path("/items/v{id}"), "", "not matching a whole path segment"},
+		{opt.Path("/reports[/{year}]"), "", "optional parts"},
+		{opt.Path("/reports/{format=json}"), "", "default value for variable {format=json}"},
+		{r.Path("/reports/[draft]"), "/reports/[draft]", ""},
+		{r.PathPrefix("/static"), "", `path prefix "/static" not ending with a slash`},
+		{r.Host("{sub}.example.com"), "", "host variables"},
+		{r.Host("example.com:8080"), "", "host port"},
+		{r.Methods(http.MethodGet, http.MethodPost), "", "several methods"},
+		{r.Path("/items").Queries("page", "{page}").Headers("X-Key", "1").Schemes("https"), "", "header matcher, schemes matcher, query matcher"},
+		{r.MatcherFunc(func(*http.Request, *RouteMatch) bool { return true }), "", "custom matcher"},
+		{r.PathPrefix("/admin/").Subrouter().NewRoute(), "/admin/", ""},
+	}
+	for i, tt := range tests {
+		pattern, err := tt.route.ServeMuxPattern()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Route %d: expected an error containing %q, got %q, %v", i, tt.err, pattern, err)
+			}
+			continue
+		}
+		if err != nil || pattern != tt.pattern {
+			t.Errorf("Route %d: expected %q, got %q, %v", i, tt.pattern, pattern, err)
+		}
+	}
+
+	parent := r.PathPrefix("/admin/")
+	parent.Subrouter()
+	if _, err := parent.ServeMuxPattern(); err == nil || !strings.Contains(err.Error(), "subrouter") {
+		t.Errorf("Expected an error for the subrouter, got %v", err)
+	}
+}
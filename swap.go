@@ -0,0 +1,170 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// SwapHandler is an http.Handler that serves requests with a Router which can
+// be replaced while serving, for instance to reload routing configuration at
+// runtime without restarting the server.
+//
+// Each request is served entirely by the router that was current when the
+// request arrived: in-flight requests finish on the old router after a swap,
+// and CurrentRouter returns the router that actually served the request.
+//
+//	h := mux.NewSwapHandler(buildRouter(config))
+//	go http.ListenAndServe(":8080", h)
+//
+//	// Later, on a configuration change:
+//	old := h.Swap(buildRouter(newConfig))
+//	log.Printf("routes reloaded: %v", mux.DiffRoutes(old, h.Router()))
+type SwapHandler struct {
+	router atomic.Pointer[Router]
+}
+
+// NewSwapHandler returns a SwapHandler serving requests with the given router.
+func NewSwapHandler(r *Router) *SwapHandler {
+	h := &SwapHandler{}
+	h.router.Store(r)
+	return h
+}
+
+// Router returns the router currently serving requests.
+func (h *SwapHandler) Router() *Router {
+	return h.router.Load()
+}
+
+// Swap atomically replaces the router serving requests and returns the
+// previous one. Requests already being served by the previous router are not
+// affected.
+func (h *SwapHandler) Swap(r *Router) *Router {
+	return h.router.Swap(r)
+}
+
+// ServeHTTP dispatches the request to the current router.
+func (h *SwapHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.router.Load().ServeHTTP(w, req)
+}
+
+// RouteDiff lists the names of the routes that differ between two routers.
+// See DiffRoutes.
+type RouteDiff struct {
+	// Routes only present in the new router.
+	Added []string
+	// Routes only present in the old router.
+	Removed []string
+	// Routes present in both routers but matching or building differently.
+	Changed []string
+}
+
+// Empty reports whether the routers have the same named routes.
+func (d RouteDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String returns a summary of the differences suitable for logging.
+func (d RouteDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	var parts []string
+	for _, p := range []struct {
+		label string
+		names []string
+	}{{"added", d.Added}, {"removed", d.Removed}, {"changed", d.Changed}} {
+		if len(p.names) > 0 {
+			parts = append(parts, p.label+": "+strings.Join(p.names, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DiffRoutes compares the named routes of two routers and their subrouters.
+// A route present in both routers is reported as changed when its host, path
+// or query templates, methods, schemes, headers or build-only flag differ.
+// Handlers are not compared. Unnamed routes are ignored.
+func DiffRoutes(oldRouter, newRouter *Router) RouteDiff {
+	oldRoutes, newRoutes := namedRoutesOf(oldRouter), namedRoutesOf(newRouter)
+	var d RouteDiff
+	for name, route := range newRoutes {
+		if o, ok := oldRoutes[name]; !ok {
+			d.Added = append(d.Added, name)
+		} else if o.signature() != route.signature() {
+			d.Changed = append(d.Changed, name)
+		}
+	}
+	for name := range oldRoutes {
+		if _, ok := newRoutes[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
+
+// namedRoutesOf returns the named routes of a router and its subrouters.
+func namedRoutesOf(r *Router) map[string]*Route {
+	routes := make(map[string]*Route)
+	if r == nil {
+		return routes
+	}
+	_ = r.Walk(func(route *Route, _ *Router, _ []*Route) error {
+		if route.name != "" {
+			routes[route.name] = route
+		}
+		return nil
+	})
+	return routes
+}
+
+// signature returns a string describing how the route matches requests and
+// builds URLs, used to compare routes.
+func (r *Route) signature() string {
+	var b strings.Builder
+	if r.regexp.host != nil {
+		b.WriteString("host=" + r.regexp.host.template + "\n")
+	}
+	if r.regexp.path != nil {
+		fmt.Fprintf(&b, "path=%s prefix=%v\n", r.regexp.path.template, r.regexp.path.regexpType == regexpTypePrefix)
+	}
+	for _, q := range r.regexp.queries {
+		b.WriteString("query=" + q.template + "\n")
+	}
+	for _, m := range r.matchers {
+		switch m := m.(type) {
+		case methodMatcher:
+			fmt.Fprintf(&b, "methods=%v\n", []string(m))
+		case schemeMatcher:
+			fmt.Fprintf(&b, "schemes=%v\n", []string(m))
+		case headerMatcher:
+			fmt.Fprintf(&b, "headers=%v\n", map[string]string(m))
+		case headerRegexMatcher:
+			fmt.Fprintf(&b, "headers=%v\n", headerRegexpStrings(m))
+		}
+	}
+	fmt.Fprintf(&b, "buildOnly=%v", r.buildOnly)
+	return b.String()
+}
+
+// headerRegexpStrings returns the expressions of a headerRegexMatcher.
+func headerRegexpStrings(m headerRegexMatcher) map[string]string {
+	s := make(map[string]string, len(m))
+	for k, v := range m {
+		if v != nil {
+			s[k] = v.String()
+		} else {
+			s[k] = ""
+		}
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSwapHandler(t *testing.T) {
+	var served []*Router
+	var mu sync.Mutex
+	record := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		served = append(served, CurrentRouter(r))
+		mu.Unlock()
+	}
+
+	r1 := NewRouter()
+	r1.HandleFunc("/", record)
+	r2 := NewRouter()
+	r2.HandleFunc("/", record)
+
+	h := NewSwapHandler(r1)
+	if h.Router() != r1 {
+		t.Fatal("Expected the initial router to be current")
+	}
+	h.ServeHTTP(NewRecorder(), newRequest(http.MethodGet, "http://localhost/"))
+	if old := h.Swap(r2); old != r1 {
+		t.Fatalf("Expected Swap to return the previous router, got %p", old)
+	}
+	h.ServeHTTP(NewRecorder(), newRequest(http.MethodGet, "http://localhost/"))
+
+	if want := []*Router{r1, r2}; !reflect.DeepEqual(served, want) {
+		t.Errorf("Expected requests to be served by %v, got %v", want, served)
+	}
+}
+
+func TestSwapHandlerConcurrent(t *testing.T) {
+	h := NewSwapHandler(NewRouter())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				h.ServeHTTP(NewRecorder(), newRequest(http.MethodGet, "http://localhost/"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r := NewRouter()
+				r.HandleFunc("/", func(http.ResponseWriter, *http.Request) {})
+				h.Swap(r)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiffRoutes(t *testing.T) {
+	old := NewRouter()
+	old.HandleFunc("/a", dummyHandler).Name("same")
+	old.HandleFunc("/b", dummyHandler).Methods(http.MethodGet).Name("methods")
+	old.HandleFunc("/c", dummyHandler).Name("removed")
+	old.PathPrefix("/api").Subrouter().HandleFunc("/d", dummyHandler).Name("nested")
+	old.HandleFunc("/unnamed", dummyHandler)
+
+	new := NewRouter()
+	new.HandleFunc("/a", dummyHandler).Name("same")
+	new.HandleFunc("/b", dummyHandler).Methods(http.MethodGet, http.MethodPost).Name("methods")
+	new.HandleFunc("/e", dummyHandler).Name("added")
+	new.PathPrefix("/api").Subrouter().HandleFunc("/d/{id}", dummyHandler).Name("nested")
+
+	got := DiffRoutes(old, new)
+	want := RouteDiff{
+		Added:   []string{"added"},
+		Removed: []string{"removed"},
+		Changed: []string{"methods", "nested"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected diff %+v, got %+v", want, got)
+	}
+	if s := got.String(); s != "added: added; removed: removed; changed: methods, nested" {
+		t.Errorf("Unexpected diff summary %q", s)
+	}
+	if d := DiffRoutes(old, old); !d.Empty() || d.String() != "no changes" {
+		t.Errorf("Expected no differences, got %v", d)
+	}
+}
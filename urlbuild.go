@@ -0,0 +1,190 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// URLOptions adds to the URLs built by Route.URLFromMap and
+// Route.URLFromStruct.
+type URLOptions struct {
+	// Query parameters appended after those declared with Route.Queries.
+	Query url.Values
+	// The fragment of the URL, without the leading '#'.
+	Fragment string
+}
+
+// URLFromMap builds a URL for the route like URL, from a map of variable
+// values instead of pairs, with the query parameters and fragment of opts
+// added. For example:
+//
+//	r.HandleFunc("/articles/{category}/{id:int}", ArticleHandler).Name("article")
+//	url, err := r.Get("article").URLFromMap(map[string]string{
+//		"category": "technology",
+//		"id":       "42",
+//	}, mux.URLOptions{Fragment: "comments"})
+//	// "/articles/technology/42#comments"
+//
+// Every variable is checked against its pattern, and the error lists all
+// the variables that are missing or don't match.
+func (r *Route) URLFromMap(vars map[string]string, opts URLOptions) (*url.URL, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	values := make(map[string]string, len(vars))
+	for k, v := range vars {
+		values[k] = v
+	}
+	values = r.buildVars(values)
+	if err := r.checkURLVars(values); err != nil {
+		return nil, err
+	}
+	u, err := r.buildURL(values)
+	if err != nil {
+		return nil, err
+	}
+	if query := opts.Query.Encode(); query != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += query
+	}
+	u.Fragment = opts.Fragment
+	return u, nil
+}
+
+// URLFromStruct builds a URL for the route like URLFromMap, from the fields
+// of v, a struct or a pointer to a struct, tagged like for Bind:
+//
+//	type ArticleParams struct {
+//		Category string `mux:"category"`
+//		ID       int    `mux:"id"`
+//		Page     int    `query:"page"`
+//	}
+//
+//	url, err := r.Get("article").URLFromStruct(ArticleParams{"technology", 42, 2}, mux.URLOptions{})
+//	// "/articles/technology/42?page=2"
+//
+// Fields tagged with mux, and fields tagged with query naming a variable of
+// the route, give the values of the route variables. Other fields tagged
+// with query are added as query parameters, one per element for slices,
+// unless they have the zero value; a pointer to a zero value is added.
+// Fields tagged with header are ignored. Values are formatted so that Bind
+// converts them back; times are formatted as dates if they are midnight UTC.
+func (r *Route) URLFromStruct(v any, opts URLOptions) (*url.URL, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("mux: URLFromStruct needs a struct or a pointer to a struct, got %T", v)
+	}
+	names, _ := r.GetVarNames()
+	vars := make(map[string]string)
+	query := url.Values{}
+	var errs []error
+	for _, f := range bindFields(rv.Type()) {
+		if f.Source == bindHeader {
+			continue
+		}
+		isVar := f.Source == bindVar || matchInArray(names, f.Name)
+		if !isVar && rv.FieldByIndex(f.Index).IsZero() {
+			continue
+		}
+		fv, ok := fieldByIndex(rv, f.Index)
+		if !ok {
+			continue
+		}
+		values, err := formatField(fv)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("mux: field %s (%s %q): %w", f.Field, f.Source, f.Name, err))
+			continue
+		}
+		if !isVar {
+			query[f.Name] = append(query[f.Name], values...)
+			continue
+		}
+		switch len(values) {
+		case 0:
+		case 1:
+			vars[f.Name] = values[0]
+		default:
+			errs = append(errs, fmt.Errorf("mux: field %s (%s %q): several values for a route variable", f.Field, f.Source, f.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for k, vs := range opts.Query {
+		query[k] = append(query[k], vs...)
+	}
+	opts.Query = query
+	return r.URLFromMap(vars, opts)
+}
+
+// fieldByIndex returns the nested field of a struct, and false if it is a
+// nil pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	v = v.FieldByIndex(index)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// formatField formats the value of a field, or of each element of a slice.
+func formatField(v reflect.Value) ([]string, error) {
+	if v.Kind() == reflect.Slice && !v.Type().Implements(textMarshalerType) {
+		values := make([]string, v.Len())
+		for i := range values {
+			s, err := formatValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = s
+		}
+		return values, nil
+	}
+	s, err := formatValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+// checkURLVars checks the values of the variables of the route against their
+// patterns, and returns an error for each variable missing or not matching.
+func (r *Route) checkURLVars(values map[string]string) error {
+	var errs []error
+	regexps := append([]*routeRegexp{r.regexp.host, r.regexp.path}, r.regexp.queries...)
+	for _, rr := range regexps {
+		if rr == nil {
+			continue
+		}
+		for k, name := range rr.varsN {
+			value, ok := values[name]
+			if !ok {
+				if _, ok := rr.defaults[name]; !ok && !rr.isOptional(k) {
+					errs = append(errs, fmt.Errorf("mux: missing route variable %q", name))
+				}
+				continue
+			}
+			if !rr.varsR[k].MatchString(value) {
+				errs = append(errs, fmt.Errorf("mux: route variable %q: value %q doesn't match %q", name, value, rr.varsR[k].String()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
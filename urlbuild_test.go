@@ -0,0 +1,136 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLFromMap(t *testing.T) {
+	r := NewRouter().OptionalParts(true)
+	article := r.Host("{tenant}.example.com").Path("/articles/{category}/{id:int}").Queries("sort", "{sort}")
+	report := r.Path("/reports/{year:int}[/{format=csv}]")
+
+	tests := []struct {
+		route *Route
+		vars  map[string]string
+		opts  URLOptions
+		url   string
+		err   string
+	}{
+		{
+			route: article,
+			vars:  map[string]string{"tenant": "acme", "category": "tech", "id": "42", "sort": "new"},
+			url:   "http://acme.example.com/articles/tech/42?sort=new",
+		},
+		{
+			route: article,
+			vars:  map[string]string{"tenant": "acme", "category": "tech", "id": "42", "sort": "new"},
+			opts:  URLOptions{Query: url.Values{"page": {"2"}, "tag": {"a b", "c"}}, Fragment: "comments"},
+			url:   "http://acme.example.com/articles/tech/42?sort=new&page=2&tag=a+b&tag=c#comments",
+		},
+		{
+			route: report,
+			vars:  map[string]string{"year": "2024"},
+			url:   "/reports/2024",
+		},
+		{
+			route: article,
+			vars:  map[string]string{"tenant": "acme", "id": "x"},
+			err: `mux: missing route variable "category"` + "\n" +
+				`mux: route variable "id": value "x" doesn't match "^-?[0-9]+$"` + "\n" +
+				`mux: missing route variable "sort"`,
+		},
+		{
+			route: report,
+			vars:  map[string]string{"year": "last"},
+			err:   `mux: route variable "year": value "last" doesn't match`,
+		},
+	}
+	for i, tt := range tests {
+		u, err := tt.route.URLFromMap(tt.vars, tt.opts)
+		if tt.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+				t.Errorf("Test %d: expected an error %q, got %v, %v", i, tt.err, u, err)
+			}
+			continue
+		}
+		if err != nil || u.String() != tt.url {
+			t.Errorf("Test %d: expected %q, got %v, %v", i, tt.url, u, err)
+		}
+	}
+}
+
+type urlParams struct {
+	Category string    `mux:"category"`
+	ID       int       `mux:"id"`
+	Sort     string    `query:"sort"`
+	Page     int       `query:"page"`
+	Tags     []string  `query:"tag"`
+	Since    time.Time `query:"since"`
+	Limit    *int      `query:"limit"`
+	Tenant   string    `header:"X-Tenant"`
+}
+
+func TestURLFromStruct(t *testing.T) {
+	r := NewRouter()
+	article := r.Path("/articles/{category}/{id:uint}").Queries("sort", "{sort}")
+
+	limit := 0
+	tests := []struct {
+		params any
+		opts   URLOptions
+		url    string
+		err    string
+	}{
+		{
+			params: urlParams{Category: "tech", ID: 42, Sort: "new"},
+			url:    "/articles/tech/42?sort=new",
+		},
+		{
+			params: &urlParams{
+				Category: "tech", ID: 42, Sort: "new", Page: 2, Tags: []string{"a", "b"},
+				Since: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Limit: &limit, Tenant: "acme",
+			},
+			opts: URLOptions{Query: url.Values{"x": {"1"}}, Fragment: "top"},
+			url:  "/articles/tech/42?sort=new&limit=0&page=2&since=2024-01-02&tag=a&tag=b&x=1#top",
+		},
+		{
+			params: urlParams{Category: "tech", ID: -1},
+			err:    `mux: route variable "id": value "-1" doesn't match`,
+		},
+		{
+			params: struct {
+				IDs []int `mux:"id"`
+			}{[]int{1, 2}},
+			err: `mux: field IDs (mux "id"): several values for a route variable`,
+		},
+		{
+			params: struct {
+				C chan int `query:"c"`
+			}{make(chan int)},
+			err: `mux: field C (query "c"): unsupported type chan int`,
+		},
+		{
+			params: "x",
+			err:    "needs a struct",
+		},
+	}
+	for i, tt := range tests {
+		u, err := article.URLFromStruct(tt.params, tt.opts)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Test %d: expected an error %q, got %v, %v", i, tt.err, u, err)
+			}
+			continue
+		}
+		if err != nil || u.String() != tt.url {
+			t.Errorf("Test %d: expected %q, got %v, %v", i, tt.url, u, err)
+		}
+	}
+}
@@ -0,0 +1,575 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ConflictKind describes how two routes conflict. See Router.Validate.
+type ConflictKind int
+
+const (
+	// ConflictUnreachable means that a route can never match because a route
+	// registered before it matches every request it would match.
+	ConflictUnreachable ConflictKind = iota
+	// ConflictDuplicate means that two routes have the same host and path
+	// templates and share at least one method.
+	ConflictDuplicate
+	// ConflictAmbiguous means that some requests match two routes, neither
+	// being more specific than the other. The route registered first wins.
+	ConflictAmbiguous
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictUnreachable:
+		return "unreachable"
+	case ConflictDuplicate:
+		return "duplicate"
+	case ConflictAmbiguous:
+		return "ambiguous"
+	}
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// RouteConflict is the error reported for two conflicting routes.
+type RouteConflict struct {
+	Kind ConflictKind
+	// The route registered last.
+	Route *Route
+	// The route registered first, which wins when both match.
+	Other *Route
+}
+
+func (c *RouteConflict) Error() string {
+	route, other := c.Route.summary(), c.Other.summary()
+	switch c.Kind {
+	case ConflictUnreachable:
+		return fmt.Sprintf("mux: route %s is unreachable, shadowed by route %s", route, other)
+	case ConflictDuplicate:
+		return fmt.Sprintf("mux: route %s duplicates route %s", route, other)
+	}
+	return fmt.Sprintf("mux: route %s is ambiguous with route %s", route, other)
+}
+
+// ValidationError is the error returned by Router.Validate.
+type ValidationError struct {
+	Conflicts []*RouteConflict
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		msgs[i] = c.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the conflicts as errors, for use with errors.Is and
+// errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		errs[i] = c
+	}
+	return errs
+}
+
+// Validate analyses the routes of the router and all its subrouters and
+// reports the routes that conflict with a route registered before them:
+//
+// - routes that can never match because an earlier route matches every
+// request they would match, e.g. "/users/me" after "/users/{id}";
+//
+// - routes with the same host and path as an earlier route and at least one
+// method in common;
+//
+// - routes that overlap an earlier route without either being more specific,
+// e.g. "/{a}/x" and "/y/{b}".
+//
+// Routes are compared on their host, path and query templates, methods,
+// schemes and headers. Routes with custom matchers are never considered to
+// shadow other routes, and routes with optional path parts are not checked.
+// Validate returns a *ValidationError, or nil if no
+// conflict was found.
+func (r *Router) Validate() error {
+	var shapes []*routeShape
+	var conflicts []*RouteConflict
+	_ = r.Walk(func(route *Route, _ *Router, _ []*Route) error {
+		n := newRouteShape(route)
+		if n == nil {
+			return nil
+		}
+		for _, e := range shapes {
+			if c := e.conflict(n, true); c != nil {
+				conflicts = append(conflicts, c)
+				if c.Kind != ConflictAmbiguous {
+					break
+				}
+			}
+		}
+		shapes = append(shapes, n)
+		return nil
+	})
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &ValidationError{Conflicts: conflicts}
+}
+
+// RejectConflicts defines whether the routes of the router are checked
+// against the routes registered before them in the whole router tree, from
+// the router RejectConflicts was first called on, including its subrouters.
+// The initial value is false. Subrouters created afterwards inherit the
+// setting.
+//
+// When true, a route that is unreachable or duplicates an earlier route, as
+// reported by Router.Validate, gets a *RouteConflict error from
+// Route.GetError and never matches, as if it was disabled. Ambiguous routes
+// are accepted.
+//
+// Routes are checked once they are fully configured: when the router is
+// frozen, see Router.Freeze, and when a request is matched after routes were
+// added to or removed from the tree. So, for instance, routes on the same path
+// with different methods are accepted:
+//
+//	r.HandleFunc("/users", ListUsers).Methods("GET")
+//	r.HandleFunc("/users", CreateUser).Methods("POST")
+func (r *Router) RejectConflicts(value bool) *Router {
+	r.rejectConflicts = value
+	if r.conflicts == nil {
+		r.conflicts = &conflictCheck{root: r}
+	}
+	// Check again with the new setting.
+	r.conflicts.gen.Add(1)
+	return r
+}
+
+// conflictCheck holds the state of the conflict check of a router tree,
+// shared by the router that enabled it and its subrouters, see
+// Router.RejectConflicts.
+type conflictCheck struct {
+	// The router whose routes and subrouters are checked.
+	root *Router
+	// Incremented every time routes are added or removed.
+	gen atomic.Uint64
+	// The generation of the last check.
+	checked atomic.Uint64
+	// Guards the check.
+	mu sync.Mutex
+}
+
+// check rejects the routes conflicting with earlier routes of the tree, unless
+// no route was added or removed since the last check.
+func (c *conflictCheck) check() {
+	if c.checked.Load() == c.gen.Load() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	gen := c.gen.Load()
+	if c.checked.Load() == gen {
+		return
+	}
+	var shapes []*routeShape
+	_ = c.root.Walk(func(route *Route, router *Router, _ []*Route) error {
+		n := newRouteShape(route)
+		var conflict *RouteConflict
+		if n != nil && router.rejectConflicts {
+			for _, e := range shapes {
+				if conflict = e.conflict(n, false); conflict != nil {
+					break
+				}
+			}
+		}
+		route.conflict.Store(conflict)
+		if n != nil && conflict == nil {
+			shapes = append(shapes, n)
+		}
+		return nil
+	})
+	c.checked.Store(gen)
+}
+
+// routeShape holds what is known about the requests matched by a route.
+type routeShape struct {
+	route   *Route
+	host    *routeRegexp
+	path    *routeRegexp
+	queries []string
+	// nil means any method or scheme.
+	methods []string
+	schemes []string
+	headers map[string]string
+	// If true, the route has matchers which can't be analysed.
+	opaque bool
+}
+
+// newRouteShape returns the shape of a route which may handle requests, or
+// nil if the route never matches or dispatches to subrouters.
+func newRouteShape(route *Route) *routeShape {
+	if route.buildOnly || route.err != nil || len(route.subrouters()) > 0 {
+		return nil
+	}
+	if route.regexp.path != nil && len(route.regexp.path.groups) > 0 {
+		// Templates with optional parts aren't compared.
+		return nil
+	}
+	s := &routeShape{
+		route: route,
+		host:  route.regexp.host,
+		path:  route.regexp.path,
+	}
+	for _, q := range route.regexp.queries {
+		s.queries = append(s.queries, q.template)
+	}
+	for _, m := range route.matchers {
+		switch m := m.(type) {
+		case *routeRegexp:
+		case methodMatcher:
+			s.methods = intersectStrings(s.methods, m)
+		case schemeMatcher:
+			s.schemes = intersectStrings(s.schemes, m)
+		case headerMatcher:
+			if s.headers == nil {
+				s.headers = make(map[string]string)
+			}
+			for k, v := range m {
+				s.headers[strings.ToLower(k)] = v
+			}
+		default:
+			s.opaque = true
+		}
+	}
+	return s
+}
+
+// conflict returns how a route registered after the route of s conflicts
+// with it, if at all. Ambiguous routes are only reported if ambiguous is
+// true.
+func (s *routeShape) conflict(n *routeShape, ambiguous bool) *RouteConflict {
+	same := s.sameTemplates(n)
+	switch {
+	case s.covers(n):
+		if same {
+			return &RouteConflict{Kind: ConflictDuplicate, Route: n.route, Other: s.route}
+		}
+		return &RouteConflict{Kind: ConflictUnreachable, Route: n.route, Other: s.route}
+	case same && !s.opaque && !n.opaque && overlapStrings(s.methods, n.methods) &&
+		equalStrings(s.schemes, n.schemes) && equalStrings(s.queries, n.queries) &&
+		equalHeaders(s.headers, n.headers):
+		return &RouteConflict{Kind: ConflictDuplicate, Route: n.route, Other: s.route}
+	case ambiguous && s.overlaps(n) && !n.covers(s):
+		return &RouteConflict{Kind: ConflictAmbiguous, Route: n.route, Other: s.route}
+	}
+	return nil
+}
+
+// sameTemplates reports whether both routes have equivalent host and path
+// templates, regardless of the names of their variables.
+func (s *routeShape) sameTemplates(n *routeShape) bool {
+	return (s.path != nil || s.host != nil) &&
+		normalizedTemplate(s.host) == normalizedTemplate(n.host) &&
+		normalizedTemplate(s.path) == normalizedTemplate(n.path) &&
+		(s.path == nil || n.path != nil && s.path.regexpType == n.path.regexpType)
+}
+
+// covers reports whether the route of s matches every request matched by the
+// route of n.
+func (s *routeShape) covers(n *routeShape) bool {
+	if s.opaque {
+		return false
+	}
+	if s.methods != nil && (n.methods == nil || !subsetStrings(n.methods, s.methods)) {
+		return false
+	}
+	if s.schemes != nil && (n.schemes == nil || !subsetStrings(n.schemes, s.schemes)) {
+		return false
+	}
+	for k, v := range s.headers {
+		if nv, ok := n.headers[k]; !ok || (v != "" && nv != v) {
+			return false
+		}
+	}
+	if !subsetStrings(s.queries, n.queries) {
+		return false
+	}
+	return templateCovers(s.host, n.host, '.') && templateCovers(s.path, n.path, '/')
+}
+
+// overlaps reports whether some requests may match the routes of s and n.
+func (s *routeShape) overlaps(n *routeShape) bool {
+	if s.opaque || n.opaque {
+		return false
+	}
+	if !overlapStrings(s.methods, n.methods) || !overlapStrings(s.schemes, n.schemes) {
+		return false
+	}
+	for k, v := range s.headers {
+		if nv, ok := n.headers[k]; ok && v != "" && nv != "" && nv != v {
+			return false
+		}
+	}
+	return templatesOverlap(s.host, n.host, '.') && templatesOverlap(s.path, n.path, '/')
+}
+
+// templatePart is a segment of a host or path template.
+type templatePart struct {
+	// The segment with variable names removed.
+	text string
+	// The pattern of the variable if the segment is exactly one variable.
+	pattern string
+	// The number of variables in the segment.
+	vars int
+}
+
+// templateParts splits a template on sep, see splitTemplate.
+func templateParts(rr *routeRegexp, sep byte) []templatePart {
+	tpl := rr.template
+	if sep == '/' {
+		tpl = strings.TrimPrefix(tpl, "/")
+	}
+	var parts []templatePart
+	var varIdx int
+	for _, seg := range splitTemplate(tpl, sep) {
+		idxs, _ := braceIndices(seg)
+		p := templatePart{vars: len(idxs) / 2}
+		var b strings.Builder
+		end := 0
+		for i := 0; i+1 < len(idxs) && varIdx < len(rr.varsR); i += 2 {
+			pattern := strings.TrimSuffix(strings.TrimPrefix(rr.varsR[varIdx].String(), "^"), "$")
+			b.WriteString(seg[end:idxs[i]] + "{" + pattern + "}")
+			if len(idxs) == 2 && idxs[0] == 0 && idxs[1] == len(seg) {
+				p.pattern = pattern
+			}
+			end = idxs[i+1]
+			varIdx++
+		}
+		b.WriteString(seg[end:])
+		p.text = b.String()
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// trimSlashPart removes the empty part left by a trailing slash.
+func trimSlashPart(parts []templatePart) []templatePart {
+	if n := len(parts); n > 1 && parts[n-1].text == "" {
+		return parts[:n-1]
+	}
+	return parts
+}
+
+// normalizedTemplate returns the template with variable names removed.
+func normalizedTemplate(rr *routeRegexp) string {
+	if rr == nil {
+		return ""
+	}
+	sep := byte('/')
+	if rr.regexpType == regexpTypeHost {
+		sep = '.'
+	}
+	var texts []string
+	for _, p := range templateParts(rr, sep) {
+		texts = append(texts, p.text)
+	}
+	return strings.Join(texts, string(sep))
+}
+
+// literalPrefix returns the part of a template before its first variable.
+func literalPrefix(tpl string) string {
+	if i := strings.IndexByte(tpl, '{'); i >= 0 {
+		return tpl[:i]
+	}
+	return tpl
+}
+
+// templateCovers reports whether the template e matches everything the
+// template n matches.
+func templateCovers(e, n *routeRegexp, sep byte) bool {
+	if e == nil {
+		return true
+	}
+	if n == nil {
+		return false
+	}
+	ep, np := templateParts(e, sep), templateParts(n, sep)
+	if e.regexpType == regexpTypePrefix {
+		if len(e.varsN) == 0 {
+			return strings.HasPrefix(literalPrefix(n.template), e.template)
+		}
+		if len(np) < len(ep) {
+			return false
+		}
+		for i := 0; i < len(ep)-1; i++ {
+			if !partCovers(ep[i], np[i], sep) {
+				return false
+			}
+		}
+		last := ep[len(ep)-1]
+		return last.vars == 0 && np[len(ep)-1].vars == 0 && strings.HasPrefix(np[len(ep)-1].text, last.text)
+	}
+	if n.regexpType == regexpTypePrefix {
+		return false
+	}
+	if e.options.strictSlash {
+		// The route matches the path with and without a trailing slash.
+		ep, np = trimSlashPart(ep), trimSlashPart(np)
+	} else if n.options.strictSlash {
+		return false
+	}
+	if len(ep) != len(np) {
+		return false
+	}
+	for i := range ep {
+		if !partCovers(ep[i], np[i], sep) {
+			return false
+		}
+	}
+	return true
+}
+
+// partCovers reports whether the template segment e matches everything the
+// segment n matches.
+func partCovers(e, n templatePart, sep byte) bool {
+	if e.text == n.text {
+		return true
+	}
+	if e.pattern == "" {
+		return false
+	}
+	if n.vars == 0 {
+		return anchoredMatch(e.pattern, n.text)
+	}
+	// A default path variable matches any other variable which can't match
+	// an empty string or span several segments.
+	return sep == '/' && e.pattern == defaultPathPattern && n.pattern != "" &&
+		!patternMatchesSlash(n.pattern) && !anchoredMatch(n.pattern, "")
+}
+
+// templatesOverlap reports whether some strings may match both templates.
+func templatesOverlap(e, n *routeRegexp, sep byte) bool {
+	if e == nil || n == nil {
+		return true
+	}
+	ep, np := templateParts(e, sep), templateParts(n, sep)
+	if e.options.strictSlash || n.options.strictSlash {
+		ep, np = trimSlashPart(ep), trimSlashPart(np)
+	}
+	ePrefix, nPrefix := e.regexpType == regexpTypePrefix, n.regexpType == regexpTypePrefix
+	for i := 0; ; i++ {
+		if i >= len(ep) || i >= len(np) {
+			return len(ep) == len(np)
+		}
+		eEnd, nEnd := ePrefix && i == len(ep)-1, nPrefix && i == len(np)-1
+		if eEnd || nEnd {
+			// The rest of the string is unconstrained.
+			return prefixPartsOverlap(ep[i], np[i], eEnd, nEnd)
+		}
+		if !partsOverlap(ep[i], np[i]) {
+			return false
+		}
+	}
+}
+
+// partsOverlap reports whether some strings may match both segments.
+func partsOverlap(e, n templatePart) bool {
+	switch {
+	case e.vars == 0 && n.vars == 0:
+		return e.text == n.text
+	case e.vars == 0:
+		return n.pattern == "" || anchoredMatch(n.pattern, e.text)
+	case n.vars == 0:
+		return e.pattern == "" || anchoredMatch(e.pattern, n.text)
+	}
+	return true
+}
+
+// prefixPartsOverlap reports whether some strings may match both segments,
+// eEnd and nEnd telling whether a segment is the last one of a prefix and may
+// be followed by anything.
+func prefixPartsOverlap(e, n templatePart, eEnd, nEnd bool) bool {
+	switch {
+	case e.vars > 0 || n.vars > 0:
+		return true
+	case eEnd && nEnd:
+		return strings.HasPrefix(e.text, n.text) || strings.HasPrefix(n.text, e.text)
+	case eEnd:
+		return strings.HasPrefix(n.text, e.text)
+	}
+	return strings.HasPrefix(e.text, n.text)
+}
+
+// defaultPathPattern is the pattern of path variables without one.
+const defaultPathPattern = "[^/]+"
+
+// anchoredMatch reports whether the pattern matches the whole string.
+func anchoredMatch(pattern, s string) bool {
+	re, err := RegexpCompileFunc("^(?:" + pattern + ")$")
+	return err == nil && re.MatchString(s)
+}
+
+// intersectStrings returns the strings of b also in a, or b if a is nil.
+func intersectStrings(a, b []string) []string {
+	if a == nil {
+		return append([]string{}, b...)
+	}
+	var s []string
+	for _, v := range b {
+		if matchInArray(a, v) {
+			s = append(s, v)
+		}
+	}
+	if s == nil {
+		s = []string{}
+	}
+	return s
+}
+
+// subsetStrings reports whether all the strings of a are in b.
+func subsetStrings(a, b []string) bool {
+	for _, v := range a {
+		if !matchInArray(b, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapStrings reports whether a and b have a string in common, a nil
+// slice meaning any string.
+func overlapStrings(a, b []string) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	for _, v := range a {
+		if matchInArray(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalStrings reports whether a and b have the same strings.
+func equalStrings(a, b []string) bool {
+	return (a == nil) == (b == nil) && subsetStrings(a, b) && subsetStrings(b, a)
+}
+
+// equalHeaders reports whether a and b have the same headers.
+func equalHeaders(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
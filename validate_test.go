@@ -0,0 +1,203 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type conflict struct {
+		kind         ConflictKind
+		route, other string
+	}
+	tests := []struct {
+		name  string
+		setup func(r *Router)
+		want  []conflict
+	}{
+		{
+			name: "no conflicts",
+			setup: func(r *Router) {
+				r.HandleFunc("/users/me", dummyHandler).Methods(http.MethodGet)
+				r.HandleFunc("/users/{id}", dummyHandler).Methods(http.MethodGet)
+				r.HandleFunc("/users/{id}", dummyHandler).Methods(http.MethodPost)
+				r.HandleFunc("/items", dummyHandler).Methods(http.MethodGet)
+				r.HandleFunc("/items", dummyHandler).Methods(http.MethodPost)
+			},
+		},
+		{
+			name: "variable shadows literal",
+			setup: func(r *Router) {
+				r.HandleFunc("/users/{id}", dummyHandler)
+				r.HandleFunc("/users/me", dummyHandler)
+			},
+			want: []conflict{{ConflictUnreachable, "/users/me", "/users/{id}"}},
+		},
+		{
+			name: "pattern shadows matching literal only",
+			setup: func(r *Router) {
+				r.HandleFunc("/users/{id:[0-9]+}", dummyHandler)
+				r.HandleFunc("/users/42", dummyHandler)
+				r.HandleFunc("/users/me", dummyHandler)
+			},
+			want: []conflict{{ConflictUnreachable, "/users/42", "/users/{id:[0-9]+}"}},
+		},
+		{
+			name: "prefix shadows subtree",
+			setup: func(r *Router) {
+				r.PathPrefix("/api").HandlerFunc(dummyHandler)
+				r.HandleFunc("/api/users", dummyHandler)
+				r.HandleFunc("/static", dummyHandler)
+			},
+			want: []conflict{{ConflictUnreachable, "/api/users", "/api"}},
+		},
+		{
+			name: "duplicate method and path",
+			setup: func(r *Router) {
+				r.HandleFunc("/users/{id}", dummyHandler).Methods(http.MethodGet, http.MethodPut)
+				r.HandleFunc("/users/{name}", dummyHandler).Methods(http.MethodPut, http.MethodDelete)
+			},
+			want: []conflict{{ConflictDuplicate, "/users/{name}", "/users/{id}"}},
+		},
+		{
+			name: "methods narrow the shadow",
+			setup: func(r *Router) {
+				r.HandleFunc("/users/{id}", dummyHandler).Methods(http.MethodGet)
+				r.HandleFunc("/users/me", dummyHandler).Methods(http.MethodGet)
+				r.HandleFunc("/users/you", dummyHandler).Methods(http.MethodPost)
+			},
+			want: []conflict{{ConflictUnreachable, "/users/me", "/users/{id}"}},
+		},
+		{
+			name: "ambiguous overlap",
+			setup: func(r *Router) {
+				r.HandleFunc("/{a}/x", dummyHandler)
+				r.HandleFunc("/y/{b}", dummyHandler)
+			},
+			want: []conflict{{ConflictAmbiguous, "/y/{b}", "/{a}/x"}},
+		},
+		{
+			name: "host and queries",
+			setup: func(r *Router) {
+				r.HandleFunc("/a", dummyHandler).Host("www.example.com")
+				r.HandleFunc("/a", dummyHandler).Host("api.example.com")
+				r.HandleFunc("/b", dummyHandler)
+				r.HandleFunc("/b", dummyHandler).Queries("page", "{page}")
+				r.HandleFunc("/c", dummyHandler).Queries("page", "{page}")
+				r.HandleFunc("/c", dummyHandler)
+			},
+			want: []conflict{{ConflictDuplicate, "/b?page={page}", "/b"}},
+		},
+		{
+			name: "subrouters",
+			setup: func(r *Router) {
+				s := r.PathPrefix("/api").Subrouter()
+				s.HandleFunc("/users/{id}", dummyHandler)
+				r.HandleFunc("/api/users/me", dummyHandler)
+			},
+			want: []conflict{{ConflictUnreachable, "/api/users/me", "/api/users/{id}"}},
+		},
+		{
+			name: "custom matchers never shadow",
+			setup: func(r *Router) {
+				r.HandleFunc("/a", dummyHandler).MatcherFunc(func(*http.Request, *RouteMatch) bool { return true })
+				r.HandleFunc("/a", dummyHandler)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			tt.setup(r)
+			err := r.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected a *ValidationError, got %v", err)
+			}
+			if len(verr.Conflicts) != len(tt.want) {
+				t.Fatalf("Expected %d conflicts, got %v", len(tt.want), err)
+			}
+			for i, c := range verr.Conflicts {
+				want := tt.want[i]
+				if c.Kind != want.kind {
+					t.Errorf("Expected %v conflict, got %v", want.kind, c)
+				}
+				for _, tpl := range []string{want.route, want.other} {
+					if !strings.Contains(c.Error(), tpl) {
+						t.Errorf("Expected %q to contain %q", c.Error(), tpl)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRejectConflicts(t *testing.T) {
+	r := NewRouter().RejectConflicts(true)
+	r.HandleFunc("/users/{id}", stringHandler("user"))
+	s := r.PathPrefix("/api").Subrouter()
+	s.HandleFunc("/items/{id}", dummyHandler)
+	me := r.HandleFunc("/users/me", dummyHandler)
+	items := s.HandleFunc("/items/{name}", dummyHandler)
+	apiNew := r.HandleFunc("/api/items/new", dummyHandler)
+	ambiguous := r.HandleFunc("/{a}/x", dummyHandler)
+	other := r.HandleFunc("/other", dummyHandler)
+	// Routes on the same path split by method don't conflict.
+	get := r.HandleFunc("/x", stringHandler("get")).Methods(http.MethodGet)
+	post := r.HandleFunc("/x", stringHandler("post")).Methods(http.MethodPost)
+
+	if err := me.GetError(); err != nil {
+		t.Fatalf("Expected routes to be checked when matching, got %v", err)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost/users/me"))
+	if rec.Body.String() != "user" {
+		t.Errorf("Expected the earlier route to match, got %q", rec.Body.String())
+	}
+
+	var conflict *RouteConflict
+	if !errors.As(me.GetError(), &conflict) || conflict.Kind != ConflictUnreachable {
+		t.Fatalf("Expected an unreachable conflict, got %v", me.GetError())
+	}
+	if conflict.Other.regexp.path.template != "/users/{id}" {
+		t.Errorf("Expected the conflict with /users/{id}, got %v", conflict)
+	}
+	if err := items.GetError(); err == nil {
+		t.Error("Expected the subrouter to inherit the setting")
+	}
+	if err := apiNew.GetError(); err == nil {
+		t.Error("Expected a conflict with a subrouter route")
+	}
+	for _, route := range []*Route{ambiguous, other, get, post} {
+		if err := route.GetError(); err != nil {
+			t.Errorf("Unexpected error for route %s: %v", route.summary(), err)
+		}
+	}
+	for method, body := range map[string]string{http.MethodGet: "get", http.MethodPost: "post"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newRequest(method, "http://localhost/x"))
+		if rec.Code != http.StatusOK || rec.Body.String() != body {
+			t.Errorf("%s /x: expected %q, got %d %q", method, body, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Routes added later are checked on the next match, and by Freeze.
+	dup := s.HandleFunc("/items/{id}", dummyHandler)
+	if _, err := r.Freeze(); err == nil || !errors.As(dup.GetError(), &conflict) || conflict.Kind != ConflictDuplicate {
+		t.Errorf("Expected Freeze to report the duplicate route, got %v", err)
+	}
+}
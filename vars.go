@@ -0,0 +1,141 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ErrVarNotFound is returned by Var when the request has no route variable
+// with the given name.
+var ErrVarNotFound = errors.New("mux: route variable not found")
+
+// Var returns the route variable with the given name converted to T. For
+// example, given a route declared with a typed variable:
+//
+//	r.HandleFunc("/articles/{id:int}", ArticleHandler)
+//
+// ...the handler can read it with:
+//
+//	id, err := mux.Var[int](req, "id")
+//
+// T can be a string, a bool, an integer or floating-point type, a time.Time
+// (parsed as a date such as "2006-01-02" or as RFC 3339), or a type whose
+// pointer implements encoding.TextUnmarshaler. Var returns an error wrapping
+// ErrVarNotFound if the variable is missing, and an error wrapping the
+// conversion error if the value can't be converted.
+func Var[T any](r *http.Request, name string) (T, error) {
+	var v T
+	s, ok := Vars(r)[name]
+	if !ok {
+		return v, fmt.Errorf("%w: %q", ErrVarNotFound, name)
+	}
+	if err := setValue(reflect.ValueOf(&v).Elem(), s); err != nil {
+		return v, fmt.Errorf("mux: invalid value %q for route variable %q: %w", s, name, err)
+	}
+	return v, nil
+}
+
+// VarInt returns the route variable with the given name converted to an int.
+// See Var.
+func VarInt(r *http.Request, name string) (int, error) {
+	return Var[int](r, name)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setValue converts s to the type of v and stores it in v.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == timeType {
+		layout := time.RFC3339
+		if len(s) == len(time.DateOnly) {
+			layout = time.DateOnly
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// formatValue formats v so that setValue converts it back. Times are
+// formatted as dates if they are midnight UTC, otherwise as RFC 3339.
+func formatValue(v reflect.Value) (string, error) {
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.Location() == time.UTC && t.Equal(t.Truncate(24*time.Hour)) {
+			return t.Format(time.DateOnly), nil
+		}
+		return t.Format(time.RFC3339Nano), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -0,0 +1,393 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"errors"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedVars(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		match    bool
+	}{
+		{"/{id:int}", "/42", true},
+		{"/{id:int}", "/-42", true},
+		{"/{id:int}", "/4a", false},
+		{"/{id:uint}", "/-42", false},
+		{"/{id:uuid}", "/123e4567-e89b-12d3-a456-426614174000", true},
+		{"/{id:uuid}", "/123e4567-e89b-12d3-a456", false},
+		{"/{s:slug}", "/hello-world-2", true},
+		{"/{s:slug}", "/Hello-World", false},
+		{"/{s:slug}", "/hello--world", false},
+		{"/{d:date}", "/2024-02-29", true},
+		{"/{d:date}", "/2024-13-01", false},
+		{"/{h:hex}", "/DEADbeef", true},
+		{"/{h:hex}", "/xyz", false},
+		{"/{a:alpha}", "/abcXYZ", true},
+		{"/{a:alpha}", "/abc1", false},
+		{"/{a:int}.{b:alpha}", "/1.x", true},
+	}
+	for _, tt := range tests {
+		r := NewRouter()
+		r.HandleFunc(tt.template, dummyHandler)
+		var match RouteMatch
+		if got := r.Match(newRequest(http.MethodGet, "http://localhost"+tt.path), &match); got != tt.match {
+			t.Errorf("%s on %s: expected match %v, got %v", tt.template, tt.path, tt.match, got)
+		}
+	}
+}
+
+func TestRouteGetVars(t *testing.T) {
+	r := NewRouter()
+	route := r.Host("{sub}.example.com").Path("/items/{id:int}/{tag:[a-z]+}").Queries("day", "{day:date}")
+	vars, err := route.GetVars()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RouteVar{
+		{Name: "sub", Matcher: MatcherHost, Pattern: "[^.]+"},
+		{Name: "id", Matcher: MatcherPath, Pattern: "-?[0-9]+", Type: "int"},
+		{Name: "tag", Matcher: MatcherPath, Pattern: "[a-z]+"},
+		{Name: "day", Matcher: MatcherQuery, Pattern: varTypePatterns["date"], Type: "date"},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("Expected %+v, got %+v", want, vars)
+	}
+
+	url, err := route.URL("sub", "www", "id", "7", "tag", "go", "day", "2024-01-02")
+	if err != nil || url.String() != "http://www.example.com/items/7/go?day=2024-01-02" {
+		t.Errorf("Unexpected URL %v, %v", url, err)
+	}
+	if _, err := route.URL("sub", "www", "id", "x", "tag", "go", "day", "2024-01-02"); err == nil {
+		t.Error("Expected the int type to validate URL variables")
+	}
+}
+
+func TestVar(t *testing.T) {
+	req := SetURLVars(newRequest(http.MethodGet, "http://localhost/"), map[string]string{
+		"id":   "42",
+		"big":  "300",
+		"neg":  "-1",
+		"f":    "1.5",
+		"b":    "true",
+		"date": "2024-02-29",
+		"ts":   "2024-02-29T10:00:00Z",
+		"ip":   "10.0.0.1",
+		"name": "gopher",
+	})
+
+	if id, err := VarInt(req, "id"); err != nil || id != 42 {
+		t.Errorf("VarInt: got %d, %v", id, err)
+	}
+	if v, err := Var[uint8](req, "id"); err != nil || v != 42 {
+		t.Errorf("Var[uint8]: got %d, %v", v, err)
+	}
+	if v, err := Var[float64](req, "f"); err != nil || v != 1.5 {
+		t.Errorf("Var[float64]: got %v, %v", v, err)
+	}
+	if v, err := Var[bool](req, "b"); err != nil || !v {
+		t.Errorf("Var[bool]: got %v, %v", v, err)
+	}
+	if v, err := Var[string](req, "name"); err != nil || v != "gopher" {
+		t.Errorf("Var[string]: got %q, %v", v, err)
+	}
+	if v, err := Var[time.Time](req, "date"); err != nil || !v.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Var[time.Time] date: got %v, %v", v, err)
+	}
+	if v, err := Var[time.Time](req, "ts"); err != nil || v.Hour() != 10 {
+		t.Errorf("Var[time.Time] timestamp: got %v, %v", v, err)
+	}
+	if v, err := Var[netip.Addr](req, "ip"); err != nil || v.String() != "10.0.0.1" {
+		t.Errorf("Var[netip.Addr]: got %v, %v", v, err)
+	}
+
+	if _, err := VarInt(req, "missing"); !errors.Is(err, ErrVarNotFound) {
+		t.Errorf("Expected ErrVarNotFound, got %v", err)
+	}
+	if _, err := Var[uint8](req, "big"); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Expected a range error, got %v", err)
+	}
+	if _, err := Var[uint](req, "neg"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected a syntax error, got %v", err)
+	}
+	if _, err := Var[[]int](req, "id"); err == nil {
+		t.Error("Expected an error for an unsupported type")
+	}
+}
+
+func ExampleVar() {
+	r := NewRouter()
+	r.HandleFunc("/articles/{id:int}", func(w http.ResponseWriter, req *http.Request) {
+		id, err := VarInt(req, "id")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = id // Load article id...
+	})
+}
+
+func TestWildcardVars(t *testing.T) {
+	r := NewRouter().StrictSlash(true)
+	route := r.HandleFunc("/files/{bucket}/{path...}", dummyHandler)
+	if err := route.GetError(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		match bool
+		vars  map[string]string
+	}{
+		{"/files/b/", true, map[string]string{"bucket": "b", "path": ""}},
+		{"/files/b/a.txt", true, map[string]string{"bucket": "b", "path": "a.txt"}},
+		{"/files/b/dir/sub/", true, map[string]string{"bucket": "b", "path": "dir/sub/"}},
+		{"/files/b", false, nil},
+	}
+	for _, tt := range tests {
+		var match RouteMatch
+		ok := r.Match(newRequest(http.MethodGet, "http://localhost"+tt.path), &match)
+		if ok != tt.match || !reflect.DeepEqual(match.Vars, tt.vars) {
+			t.Errorf("%s: expected %v %v, got %v %v", tt.path, tt.match, tt.vars, ok, match.Vars)
+		}
+		if ok && match.Handler == nil {
+			t.Errorf("%s: expected a handler", tt.path)
+		}
+	}
+	// StrictSlash doesn't redirect wildcard routes.
+	rec := NewRecorder()
+	r.ServeHTTP(rec, newRequest(http.MethodGet, "http://localhost/files/b/dir/"))
+	if rec.Code == http.StatusMovedPermanently {
+		t.Error("Expected no redirect for a wildcard route")
+	}
+
+	u, err := route.URL("bucket", "my bucket", "path", "dir/a b?.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.String(), "/files/my%20bucket/dir/a%20b%3F.txt"; got != want {
+		t.Errorf("Expected URL %q, got %q", want, got)
+	}
+	if u.Path != "/files/my bucket/dir/a b?.txt" {
+		t.Errorf("Unexpected URL path %q", u.Path)
+	}
+
+	vars, _ := route.GetVars()
+	if len(vars) != 2 || vars[0].Wildcard || !vars[1].Wildcard || vars[1].Name != "path" {
+		t.Errorf("Expected path to be reported as a wildcard, got %+v", vars)
+	}
+	if names, _ := route.GetVarNames(); !reflect.DeepEqual(names, []string{"bucket", "path"}) {
+		t.Errorf("Unexpected variable names %v", names)
+	}
+}
+
+func TestWildcardVarsEncodedPath(t *testing.T) {
+	r := NewRouter().UseEncodedPath()
+	route := r.HandleFunc("/files/{path...}", dummyHandler)
+
+	var match RouteMatch
+	if !r.Match(newRequest(http.MethodGet, "http://localhost/files/a%2Fb/c"), &match) {
+		t.Fatal("Expected a match")
+	}
+	if got := match.Vars["path"]; got != "a%2Fb/c" {
+		t.Errorf("Expected the encoded path, got %q", got)
+	}
+	u, err := route.URLPath("path", match.Vars["path"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.EscapedPath(); got != "/files/a%2Fb/c" {
+		t.Errorf("Expected the URL to round-trip, got %q", got)
+	}
+}
+
+func TestWildcardErrors(t *testing.T) {
+	for _, tpl := range []string{"/{a...}/b", "/{a...}/{b...}", "/{a...}x"} {
+		if err := NewRouter().HandleFunc(tpl, dummyHandler).GetError(); err == nil {
+			t.Errorf("%s: expected an error", tpl)
+		}
+	}
+	if err := NewRouter().Host("{a...}").GetError(); err == nil {
+		t.Error("Expected an error for a wildcard in a host")
+	}
+}
+
+func TestOptionalParts(t *testing.T) {
+	r := NewRouter().OptionalParts(true)
+	route := r.HandleFunc("/reports[/{year:[0-9]{4}}][.{format=json:[a-z]+}]", dummyHandler)
+	if err := route.GetError(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		match bool
+		vars  map[string]string
+	}{
+		{"/reports", true, map[string]string{"format": "json"}},
+		{"/reports/2024", true, map[string]string{"year": "2024", "format": "json"}},
+		{"/reports.csv", true, map[string]string{"format": "csv"}},
+		{"/reports/2024.csv", true, map[string]string{"year": "2024", "format": "csv"}},
+		{"/reports/24", false, nil},
+		{"/reports/", false, nil},
+	}
+	for _, tt := range tests {
+		var match RouteMatch
+		ok := r.Match(newRequest(http.MethodGet, "http://localhost"+tt.path), &match)
+		if ok != tt.match || !reflect.DeepEqual(match.Vars, tt.vars) {
+			t.Errorf("%s: expected %v %v, got %v %v", tt.path, tt.match, tt.vars, ok, match.Vars)
+		}
+	}
+
+	urls := []struct {
+		pairs []string
+		want  string
+	}{
+		{nil, "/reports"},
+		{[]string{"year", "2024"}, "/reports/2024"},
+		{[]string{"format", "csv"}, "/reports.csv"},
+		{[]string{"year", "2024", "format", "csv"}, "/reports/2024.csv"},
+	}
+	for _, tt := range urls {
+		u, err := route.URL(tt.pairs...)
+		if err != nil || u.String() != tt.want {
+			t.Errorf("URL(%v): expected %q, got %v, %v", tt.pairs, tt.want, u, err)
+		}
+	}
+	if _, err := route.URL("year", "24"); err == nil {
+		t.Error("Expected an error for an invalid optional variable")
+	}
+
+	vars, _ := route.GetVars()
+	want := []RouteVar{
+		{Name: "year", Pattern: "[0-9]{4}", Optional: true},
+		{Name: "format", Pattern: "[a-z]+", Optional: true, Default: "json"},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("Expected vars %+v, got %+v", want, vars)
+	}
+}
+
+func TestDefaultVars(t *testing.T) {
+	r := NewRouter().OptionalParts(true)
+	route := r.HandleFunc("/{lang=en}/docs/{page}", dummyHandler).Queries("v", "{v=1:int}")
+	var match RouteMatch
+	if !r.Match(newRequest(http.MethodGet, "http://localhost/fr/docs/intro?v=2"), &match) {
+		t.Fatal("Expected a match")
+	}
+	if want := map[string]string{"lang": "fr", "page": "intro", "v": "2"}; !reflect.DeepEqual(match.Vars, want) {
+		t.Errorf("Expected vars %v, got %v", want, match.Vars)
+	}
+	u, err := route.URL("page", "intro")
+	if err != nil || u.String() != "/en/docs/intro?v=1" {
+		t.Errorf("Expected the URL to use the defaults, got %v, %v", u, err)
+	}
+	if _, err := route.URL(); err == nil {
+		t.Error("Expected an error for a missing variable without a default")
+	}
+}
+
+func TestOptionalPartsErrors(t *testing.T) {
+	tests := []struct {
+		tpl, err string
+	}{
+		{"/a[/{b}[/{c}]]", "nested optional parts"},
+		{"/a[/{b}", "unbalanced brackets"},
+		{"/a]", "unbalanced brackets"},
+		{"/{id=x:[0-9]+}", "doesn't match the pattern"},
+	}
+	for _, tt := range tests {
+		err := NewRouter().OptionalParts(true).HandleFunc(tt.tpl, dummyHandler).GetError()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.tpl, tt.err, err)
+		}
+	}
+	// Brackets are literal in hosts and inside variable patterns.
+	if err := NewRouter().OptionalParts(true).Host("[::1]").Path("/{id:[0-9]+}").GetError(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestOptionalPartsDisabled(t *testing.T) {
+	r := NewRouter()
+	for _, tpl := range []string{"/items[]", "/v[1]/x", "/a[b", "/d/{a=b}"} {
+		if err := r.HandleFunc(tpl, stringHandler(tpl)).GetError(); err != nil {
+			t.Errorf("%s: unexpected error %v", tpl, err)
+		}
+	}
+	tests := []struct {
+		path  string
+		match string
+		vars  map[string]string
+	}{
+		{"/items%5B%5D", "/items[]", nil},
+		{"/items", "", nil},
+		{"/v%5B1%5D/x", "/v[1]/x", nil},
+		{"/v/x", "", nil},
+		{"/a%5Bb", "/a[b", nil},
+		{"/d/c", "/d/{a=b}", map[string]string{"a=b": "c"}},
+	}
+	for _, tt := range tests {
+		var match RouteMatch
+		ok := r.Match(newRequest(http.MethodGet, "http://localhost"+tt.path), &match)
+		if ok != (tt.match != "") {
+			t.Errorf("%s: expected a match %v, got %v", tt.path, tt.match != "", ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if tpl, _ := match.Route.GetPathTemplate(); tpl != tt.match || !reflect.DeepEqual(match.Vars, tt.vars) {
+			t.Errorf("%s: expected route %q with vars %v, got %q with %v", tt.path, tt.match, tt.vars, tpl, match.Vars)
+		}
+	}
+}
+
+func TestTemplateVariants(t *testing.T) {
+	r := NewRouter().OptionalParts(true)
+	tests := []struct {
+		tpl  string
+		want []TemplateVariant
+	}{
+		{"/a/{b:[0-9]{2}}", []TemplateVariant{{"/a/{b}", []string{"b"}}}},
+		{"/a[/{b}][.{c=json}]", []TemplateVariant{
+			{"/a", nil},
+			{"/a/{b}", []string{"b"}},
+			{"/a.{c}", []string{"c"}},
+			{"/a/{b}.{c}", []string{"b", "c"}},
+		}},
+		{"/r[/list]/{id}", []TemplateVariant{{"/r/{id}", []string{"id"}}, {"/r/list/{id}", []string{"id"}}}},
+		{"/f/{p...}", []TemplateVariant{{"/f/{p}", []string{"p"}}}},
+	}
+	for _, tt := range tests {
+		got, err := r.Path(tt.tpl).GetPathVariants()
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v, %v", tt.tpl, tt.want, got, err)
+		}
+	}
+
+	route := r.Host("{tenant:[a-z]+}.example.com").Queries("q", "{q}", "debug", "1", "limit", "{limit=10:uint}")
+	host, err := route.GetHostVariant()
+	if want := (TemplateVariant{"{tenant}.example.com", []string{"tenant"}}); err != nil || !reflect.DeepEqual(host, want) {
+		t.Errorf("Expected host %v, got %v, %v", want, host, err)
+	}
+	queries, err := route.GetQueriesVariants()
+	want := []TemplateVariant{{"q={q}", []string{"q"}}, {"debug=1", nil}, {"limit={limit}", []string{"limit"}}}
+	if err != nil || !reflect.DeepEqual(queries, want) {
+		t.Errorf("Expected queries %v, got %v, %v", want, queries, err)
+	}
+	if _, err := route.GetPathVariants(); err == nil {
+		t.Error("Expected an error for a route without a path")
+	}
+}